@@ -1,24 +1,51 @@
 package anim
 
 import (
+	"path/filepath"
+	"strings"
+
 	"github.com/xackery/engine/animation"
+	"github.com/xackery/engine/core"
 	"github.com/xackery/engine/graphic"
 	"github.com/xackery/engine/math32"
 	"github.com/xackery/quail/common"
 )
 
+// Generate returns one animation per archive animation and rigged mesh, with channels
+// driving the skeleton joints named by each bone animation. Animations that move
+// none of a mesh's joints are skipped for that mesh
 func Generate(in []*common.Animation, meshes []*graphic.RiggedMesh) ([]*animation.Animation, error) {
+	return GenerateShared(in, nil, meshes)
+}
+
+// GenerateShared is Generate for animations borrowed from another archive, whose bone
+// names may start with one of sourceModels instead of the name of the animated mesh
+func GenerateShared(in []*common.Animation, sourceModels []string, meshes []*graphic.RiggedMesh) ([]*animation.Animation, error) {
 	anims := make([]*animation.Animation, 0)
 
 	for _, mesh := range meshes {
+		joints := make(map[string]*core.Node)
+		if mesh.Skeleton() != nil {
+			for _, joint := range mesh.Skeleton().Bones() {
+				joints[strings.ToLower(joint.Name())] = joint
+				joints[BoneKey(joint.Name(), mesh.Name())] = joint
+			}
+		}
+
 		for _, entry := range in {
 			//fmt.Println("anim:", entry.Header.Name)
+			anim := animation.NewAnimation()
+			anim.SetName(entry.Header.Name)
+			anim.SetLoop(true)
+			anim.SetPaused(false)
+			anim.SetSpeed(1)
+			channels := 0
+
 			for _, boneAnim := range entry.Bones {
-				anim := animation.NewAnimation()
-				anim.SetName(entry.Header.Name)
-				anim.SetLoop(true)
-				anim.SetPaused(false)
-				anim.SetSpeed(1)
+				joint := findJoint(joints, boneAnim.Name, sourceModels)
+				if joint == nil || len(boneAnim.Frames) == 0 {
+					continue
+				}
 				var keyframes math32.ArrayF32
 				var posValues math32.ArrayF32
 				var rotValues math32.ArrayF32
@@ -28,23 +55,59 @@ func Generate(in []*common.Animation, meshes []*graphic.RiggedMesh) ([]*animatio
 					keyframes = append(keyframes, float32(i))
 					posValues = append(posValues, keyframe.Translation.X, keyframe.Translation.Y, keyframe.Translation.Z)
 					rotValues = append(rotValues, keyframe.Rotation.X, keyframe.Rotation.Y, keyframe.Rotation.Z, keyframe.Rotation.W)
-					scaleValues = append(scaleValues, keyframe.Scale.X, keyframe.Scale.Y, keyframe.Scale.Z)
+					scale := keyframe.Scale
+					if scale.X == 0 && scale.Y == 0 && scale.Z == 0 {
+						scale = common.Vector3{X: 1, Y: 1, Z: 1}
+					}
+					scaleValues = append(scaleValues, scale.X, scale.Y, scale.Z)
 				}
-				posChan := animation.NewPositionChannel(mesh)
+				posChan := animation.NewPositionChannel(joint)
 				posChan.SetBuffers(keyframes, posValues)
 				anim.AddChannel(posChan)
 
-				rotChan := animation.NewRotationChannel(mesh)
+				rotChan := animation.NewRotationChannel(joint)
 				rotChan.SetBuffers(keyframes, rotValues)
 				anim.AddChannel(rotChan)
 
-				scaleChan := animation.NewScaleChannel(mesh)
+				scaleChan := animation.NewScaleChannel(joint)
 				scaleChan.SetBuffers(keyframes, scaleValues)
 				anim.AddChannel(scaleChan)
-
-				anims = append(anims, anim)
+				channels++
 			}
+			if channels == 0 {
+				continue
+			}
+			anims = append(anims, anim)
 		}
 	}
 	return anims, nil
 }
+
+// findJoint returns the joint animated by the bone animation name, matching the exact
+// name first, then the name without a source model prefix
+func findJoint(joints map[string]*core.Node, name string, sourceModels []string) *core.Node {
+	joint, ok := joints[strings.ToLower(name)]
+	if ok {
+		return joint
+	}
+	for _, model := range append([]string{""}, sourceModels...) {
+		joint, ok = joints[BoneKey(name, model)]
+		if ok {
+			return joint
+		}
+	}
+	return nil
+}
+
+// BoneKey normalizes a bone name so bones of different races match, dropping a leading
+// model name, such as the race code of wld bones, and the _dag suffix
+func BoneKey(name string, model string) string {
+	key := strings.TrimSuffix(strings.ToLower(name), "_dag")
+	model = strings.ToLower(model)
+	model = strings.TrimSuffix(model, filepath.Ext(model))
+	model = strings.TrimSuffix(model, "_actordef")
+	if model != "" {
+		key = strings.TrimPrefix(key, model)
+	}
+	return strings.TrimLeft(key, "_")
+}
@@ -0,0 +1,78 @@
+package anim
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xackery/engine/geometry"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/quail-view/internal/fixture"
+	"github.com/xackery/quail-view/skeleton"
+	"github.com/xackery/quail/common"
+)
+
+func TestGenerate(t *testing.T) {
+	q := fixture.Archive()
+	model := q.Models[1]
+	skel, err := skeleton.Generate(model.Bones)
+	if err != nil {
+		t.Fatalf("generate skeleton: %s", err)
+	}
+	rig := graphic.NewRiggedMesh(graphic.NewMesh(geometry.NewGeometry(), nil))
+	rig.SetName(model.Header.Name)
+	rig.SetSkeleton(skel)
+
+	anims, err := Generate(q.Animations, []*graphic.RiggedMesh{rig})
+	if err != nil {
+		t.Fatalf("generate: %s", err)
+	}
+	if len(anims) != 1 || anims[0].Name() != "c01" {
+		t.Fatalf("expected animation c01, got %d animations", len(anims))
+	}
+	anims[0].Update(2)
+	joint := skel.Bones()[1]
+	if rotation := joint.Quaternion(); rotation.Z <= 0 {
+		t.Fatalf("joint %s not rotated by the animation: %v", joint.Name(), rotation)
+	}
+
+	// bones named after another race still match once their prefix is dropped
+	shared, err := GenerateShared(renamed(q.Animations[0], "rigged", "other"), []string{"other"}, []*graphic.RiggedMesh{rig})
+	if err != nil {
+		t.Fatalf("generate shared: %s", err)
+	}
+	if len(shared) != 1 {
+		t.Fatalf("expected one shared animation, got %d", len(shared))
+	}
+}
+
+func TestBoneKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		want  string
+	}{
+		{"HUMPE_DAG", "HUM_ACTORDEF", "pe"},
+		{"ELFPE_DAG", "elf", "pe"},
+		{"pelvis", "elf", "pelvis"},
+		{"elf_head", "elf.mod", "head"},
+		{"HEAD_POINT", "", "head_point"},
+	}
+	for _, tt := range tests {
+		got := BoneKey(tt.name, tt.model)
+		if got != tt.want {
+			t.Fatalf("BoneKey(%s, %s) = %s, expected %s", tt.name, tt.model, got, tt.want)
+		}
+	}
+}
+
+// renamed returns a copy of animation whose bone names start with to instead of from
+func renamed(animation *common.Animation, from string, to string) []*common.Animation {
+	out := *animation
+	out.Bones = nil
+	for _, bone := range animation.Bones {
+		b := *bone
+		b.Name = to + strings.TrimPrefix(b.Name, from)
+		out.Bones = append(out.Bones, &b)
+	}
+	return []*common.Animation{&out}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail-view/export"
+	"github.com/xackery/quail/common"
+)
+
+// exportAnimation writes the playing animation on the focused model, or the first
+// rigged model, as glb and bvh files next to the archive
+func (gv *g3nView) exportAnimation() error {
+	if gv.archiveQuail == nil {
+		return fmt.Errorf("open an archive before exporting an animation")
+	}
+	model := gv.archiveModels[gv.focusName]
+	if model == nil || len(model.Bones) == 0 {
+		model = nil
+		for _, m := range gv.archiveQuail.Models {
+			if len(m.Bones) > 0 {
+				model = m
+				break
+			}
+		}
+	}
+	if model == nil {
+		return fmt.Errorf("no rigged model to export an animation for")
+	}
+	animation := gv.findAnimation(gv.animName)
+	if animation == nil {
+		return fmt.Errorf("no animation playing")
+	}
+
+	clip, err := export.NewClip(model, animation)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(gv.archivePath)
+	paths := []string{}
+	for _, format := range []struct {
+		ext   string
+		write func(io.Writer, *export.Clip) error
+	}{
+		{".glb", export.GLTF},
+		{".bvh", export.BVH},
+	} {
+		path := filepath.Join(dir, clip.FileName(format.ext))
+		err = writeExport(path, clip, format.write)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, path)
+	}
+	gv.ed.Show(fmt.Sprintf("Exported %s of %s to %s", clip.Name, clip.Source, strings.Join(paths, ", ")))
+	return nil
+}
+
+// writeExport creates path and writes clip to it
+func writeExport(path string, clip *export.Clip, write func(io.Writer, *export.Clip) error) error {
+	w, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	defer w.Close()
+	err = write(w, clip)
+	if err != nil {
+		return fmt.Errorf("export %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// findAnimation returns the archive or borrowed animation shown in the animation menu as name
+func (gv *g3nView) findAnimation(name string) *common.Animation {
+	if name == "" {
+		return nil
+	}
+	for _, animation := range gv.archiveQuail.Animations {
+		if animation.Header.Name == name {
+			return animation
+		}
+	}
+	for _, source := range gv.animSources {
+		for _, animation := range source.animations {
+			if source.animName(animation) == name {
+				return animation
+			}
+		}
+	}
+	return nil
+}
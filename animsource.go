@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/xackery/engine/animation"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/quail-view/anim"
+	"github.com/xackery/quail/common"
+	"github.com/xackery/quail/quail"
+)
+
+// animSource is an archive lending its animations to models of the opened archive
+type animSource struct {
+	path       string
+	model      string   // Model the animations were attached to, empty for every rigged model
+	models     []string // Models of the source archive, their names prefix its bone names
+	animations []*common.Animation
+}
+
+// animName returns the name animation is listed as in the animation menu
+func (source *animSource) animName(animation *common.Animation) string {
+	return fmt.Sprintf("%s (%s)", animation.Header.Name, filepath.Base(source.path))
+}
+
+// addAnimSource attaches the animations of the archive at path to the focused model,
+// or to every rigged model when none is focused
+func (gv *g3nView) addAnimSource(path string) error {
+	if gv.archivePath == "" {
+		return fmt.Errorf("open an archive before adding an animation source")
+	}
+	return gv.attachAnimSource(&animSource{path: path, model: gv.focusName})
+}
+
+// attachAnimSource generates the animations of source for its target models
+func (gv *g3nView) attachAnimSource(source *animSource) error {
+	q := &quail.Quail{}
+	err := q.PfsRead(source.path)
+	if err != nil {
+		return fmt.Errorf("pfs read %s: %w", source.path, err)
+	}
+	if len(q.Animations) == 0 {
+		return fmt.Errorf("%s has no animations", filepath.Base(source.path))
+	}
+
+	isTarget := false
+	for _, model := range gv.archiveQuail.Models {
+		if len(model.Bones) > 0 && (source.model == "" || model.Header.Name == source.model) {
+			isTarget = true
+			break
+		}
+	}
+	if !isTarget {
+		if source.model != "" {
+			return fmt.Errorf("%s has no bones to animate", source.model)
+		}
+		return fmt.Errorf("no rigged models to animate")
+	}
+
+	// bone names of the source models are matched without their race prefix
+	for _, model := range q.Models {
+		source.models = append(source.models, model.Header.Name)
+	}
+	source.animations = q.Animations
+
+	// models not built yet get the animations of every source once they're shown
+	anims := []*animation.Animation{}
+	isBuilt := false
+	for _, am := range gv.loader.models {
+		if am.rig == nil || (source.model != "" && am.model.Header.Name != source.model) {
+			continue
+		}
+		isBuilt = true
+		borrowed, err := sourceAnims(source, am.rig)
+		if err != nil {
+			return err
+		}
+		am.anims = append(am.anims, borrowed...)
+		anims = append(anims, borrowed...)
+	}
+	if isBuilt && len(anims) == 0 {
+		return fmt.Errorf("no bones of %s match the animations of %s", targetName(source), filepath.Base(source.path))
+	}
+	gv.anims = append(gv.anims, anims...)
+	gv.animSources = append(gv.animSources, source)
+	gv.refreshAnimMenu()
+	if len(anims) > 0 {
+		gv.setAnimation(anims[0].Name())
+	}
+	fmt.Println("attached", len(anims), "animations from", source.path, "to", targetName(source))
+	return nil
+}
+
+// sourceAnims generates the animations of source for rig, named after the source archive
+func sourceAnims(source *animSource, rig *graphic.RiggedMesh) ([]*animation.Animation, error) {
+	anims, err := anim.GenerateShared(source.animations, source.models, []*graphic.RiggedMesh{rig})
+	if err != nil {
+		return nil, fmt.Errorf("generate anim: %w", err)
+	}
+	for _, a := range anims {
+		a.SetName(fmt.Sprintf("%s (%s)", a.Name(), filepath.Base(source.path)))
+	}
+	return anims, nil
+}
+
+// clearAnimSources removes the animations borrowed from other archives
+func (gv *g3nView) clearAnimSources() {
+	if len(gv.animSources) == 0 {
+		return
+	}
+	gv.animSources = nil
+	// reloading resets the joints posed by the borrowed animations
+	gv.animName = ""
+	err := gv.reload(gv.archivePath)
+	if err != nil {
+		gv.ed.Show(err.Error())
+	}
+}
+
+// targetName describes the models an animation source is attached to
+func targetName(source *animSource) string {
+	if source.model == "" {
+		return "every rigged model"
+	}
+	return source.model
+}
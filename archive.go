@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/window"
+	"github.com/xackery/quail-view/bookmark"
+	"github.com/xackery/quail-view/settings"
+	"github.com/xackery/quail/common"
+	"github.com/xackery/quail/quail"
+)
+
+// openArchive loads an EQ archive into the scene, replacing any archive already shown.
+// Models are listed from their metadata and built in the background once in view
+func (gv *g3nView) openArchive(path string) error {
+	q := &quail.Quail{}
+	err := q.PfsRead(path)
+	if err != nil {
+		return fmt.Errorf("pfs read: %w", err)
+	}
+
+	gv.closeArchive()
+	gv.archivePath = path
+	gv.archiveQuail = q
+	gv.archiveModels = make(map[string]*common.Model)
+	for _, model := range q.Models {
+		gv.archiveModels[model.Header.Name] = model
+	}
+	gv.watcher.add(path)
+	gv.settings.AddRecent(path)
+	gv.refreshRecentMenu()
+	gv.saveSettings()
+	gv.IWindow.(*window.GlfwWindow).SetTitle(fmt.Sprintf("quail-view v%s - %s", Version, filepath.Base(path)))
+
+	err = gv.loadBookmarks(path)
+	if err != nil {
+		fmt.Println("Failed to load bookmarks:", err)
+		gv.ed.Show(err.Error())
+	}
+
+	// zone archives keep terrain at world coordinates and instance placeable objects
+	gv.isZone = q.Zone != nil
+	maxWidth := gv.loadArchive(path, q)
+
+	gv.refreshAnimMenu()
+	gv.refreshInfo()
+	gv.refreshMaterialPanel()
+	gv.refreshSkeleton()
+
+	gv.cam.SetPosition(0, 0, float32(maxWidth))
+	gv.setFpsCamera(gv.settings.FlyCamera)
+
+	// point lights of the rig are scaled to the widest model
+	gv.sceneWidth = float32(maxWidth)
+	if gv.rig != nil {
+		err = gv.applyRig(gv.rig)
+		if err != nil {
+			return fmt.Errorf("apply lighting: %w", err)
+		}
+	}
+	return nil
+}
+
+// closeArchive removes the models of the opened archive from the scene
+func (gv *g3nView) closeArchive() {
+	if gv.loader != nil {
+		gv.loader.close()
+		gv.loader = nil
+		gv.loadBar.Show(false)
+	}
+	gv.watcher.remove(gv.archivePath)
+	gv.closeCompare()
+	gv.clearHighlight()
+	gv.clearSkeleton()
+	gv.pickInfo.SetVisible(false)
+	gv.cp.Show(false)
+	for _, node := range gv.archiveNodes {
+		gv.scene.Remove(node)
+		node.Dispose()
+	}
+	gv.archiveNodes = nil
+	gv.anims = nil
+	gv.animName = ""
+	gv.animSources = nil
+	gv.archivePath = ""
+	gv.isZone = false
+	gv.focusName = ""
+	gv.archiveQuail = nil
+	gv.archiveModels = nil
+	gv.archiveWeights = nil
+	for _, fm := range gv.focusModels {
+		fm.node = nil
+		fm.mi.SetVisible(false)
+	}
+	gv.refreshAnimMenu()
+	gv.refreshInfo()
+	gv.refreshMaterialPanel()
+	gv.refreshSkeleton()
+}
+
+// buildAnimMenu creates the animation menu, filled when an archive is opened
+func (gv *g3nView) buildAnimMenu(mb *gui.Menu) {
+	gv.animMenu = gui.NewMenu()
+	gv.animMenu.AddOption("Add animation source").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		if gv.archivePath == "" {
+			gv.ed.Show("Open an archive before adding an animation source")
+			return
+		}
+		gv.isComparing = false
+		gv.isAnimSource = true
+		gv.fs.Show(true)
+	})
+	gv.animMenu.AddOption("Clear animation sources").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.clearAnimSources()
+	})
+	gv.animMenu.AddOption("Export animation").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		err := gv.exportAnimation()
+		if err != nil {
+			gv.ed.Show(err.Error())
+		}
+	})
+	gv.animMenu.AddSeparator()
+	mb.AddMenu("Animation", gv.animMenu)
+}
+
+// refreshAnimMenu lists the animations of the opened archive
+func (gv *g3nView) refreshAnimMenu() {
+	names := []string{}
+	for _, a := range gv.anims {
+		isNew := true
+		for _, name := range names {
+			if name == a.Name() {
+				isNew = false
+				break
+			}
+		}
+		if isNew {
+			names = append(names, a.Name())
+		}
+	}
+
+	// menu items can't be removed, existing ones are reused and the rest hidden
+	for len(gv.animItems) < len(names) {
+		mi := gv.animMenu.AddOption("")
+		mi.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+			gv.setAnimation(mi.Id())
+		})
+		gv.animItems = append(gv.animItems, mi)
+	}
+	for i, mi := range gv.animItems {
+		if i >= len(names) {
+			mi.SetVisible(false)
+			continue
+		}
+		mi.SetId(names[i])
+		mi.SetText(names[i])
+		mi.SetIcon(getIcon(names[i] == gv.animName))
+		mi.SetVisible(true)
+	}
+}
+
+// setAnimation sets which animation plays on the rigged meshes
+func (gv *g3nView) setAnimation(name string) {
+	gv.animName = name
+	for _, a := range gv.anims {
+		if a.Name() == name {
+			a.Reset()
+		}
+	}
+	for _, mi := range gv.animItems {
+		mi.SetIcon(getIcon(mi.Id() == name))
+	}
+	fmt.Println("Playing animation", name)
+}
+
+// updateAnimations advances every channel of the active animation
+func (gv *g3nView) updateAnimations(delta float32) {
+	for _, a := range gv.anims {
+		if a.Name() != gv.animName {
+			continue
+		}
+		a.Update(delta)
+	}
+}
+
+// buildRecentMenu creates the File > Recent submenu
+func (gv *g3nView) buildRecentMenu(m *gui.Menu) {
+	gv.recentMenu = gui.NewMenu()
+	for i := 0; i < settings.RecentMax; i++ {
+		mi := gv.recentMenu.AddOption(fmt.Sprintf("Recent %d", i))
+		mi.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+			path := mi.Id()
+			err := gv.openModel(path)
+			if err != nil {
+				gv.ed.Show(err.Error())
+			}
+		})
+		mi.SetVisible(false)
+		gv.recentItems = append(gv.recentItems, mi)
+	}
+	m.AddMenu("Recent", gv.recentMenu)
+	gv.refreshRecentMenu()
+}
+
+// refreshRecentMenu lists the recent files from settings
+func (gv *g3nView) refreshRecentMenu() {
+	for i, mi := range gv.recentItems {
+		if i >= len(gv.settings.Recent) {
+			mi.SetVisible(false)
+			continue
+		}
+		path := gv.settings.Recent[i]
+		mi.SetId(path)
+		mi.SetText(fmt.Sprintf("%d. %s", i+1, filepath.Base(path)))
+		mi.SetVisible(true)
+	}
+}
+
+// saveSession records the open archive, focus, camera and animation for the next launch
+func (gv *g3nView) saveSession() {
+	if gv.archivePath == "" {
+		gv.settings.Session = nil
+		return
+	}
+	gv.settings.Session = &settings.Session{
+		Archive:   gv.archivePath,
+		Focus:     gv.focusName,
+		Camera:    gv.cameraPose("session"),
+		Animation: gv.animName,
+	}
+}
+
+// restoreSession reopens what was open when the viewer was last closed
+func (gv *g3nView) restoreSession() error {
+	session := gv.settings.Session
+	if session == nil || session.Archive == "" {
+		return fmt.Errorf("no previous session")
+	}
+	err := gv.openArchive(session.Archive)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", session.Archive, err)
+	}
+	gv.restoreView(session.Focus, session.Camera, session.Animation)
+	return nil
+}
+
+// restoreView focuses the model named focus, then applies the camera pose and animation.
+// A model not built yet is restored once it's shown
+func (gv *g3nView) restoreView(focus string, pose *bookmark.Bookmark, animName string) {
+	gv.whenModelShown(focus, func() {
+		for _, fm := range gv.focusModels {
+			if fm.node != nil && fm.node.Name() == focus {
+				fm.onClick("", nil)
+				break
+			}
+		}
+		if pose != nil {
+			gv.applyBookmark(pose)
+		}
+		if animName != "" {
+			gv.setAnimation(animName)
+		}
+	})
+}
@@ -0,0 +1,497 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xackery/engine/animation"
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/anim"
+	"github.com/xackery/quail-view/mesh"
+	"github.com/xackery/quail-view/skeleton"
+	"github.com/xackery/quail-view/skin"
+	"github.com/xackery/quail-view/zone"
+	"github.com/xackery/quail/common"
+	"github.com/xackery/quail/quail"
+)
+
+// loadFrameBudget is how long a frame may spend building prepared models
+const loadFrameBudget = 8 * time.Millisecond
+
+const (
+	modelUnloaded = iota
+	modelPreparing
+	modelPrepared
+	modelShown
+	modelFailed
+)
+
+// archiveLoader builds the models of the opened archive the first time they're in
+// view or focused. Workers prepare requested models, the render thread builds them
+// and evicts models not viewed recently once the memory budget is exceeded
+type archiveLoader struct {
+	path       string
+	q          *quail.Quail
+	weights    map[string]skin.Weights // Written before any result is sent
+	models     []*archiveModel
+	jobs       chan int
+	result     chan preparedResult
+	canceled   int32
+	isEager    bool // Zone archives build every model, objects are instanced from them
+	isPlaced   bool // Zone objects were placed
+	placeables map[string]*graphic.Mesh
+	requested  int // Models requested since the loader was last idle
+	shown      int // Requested models shown since the loader was last idle
+}
+
+// archiveModel is a model of the opened archive and the scene node built for it
+type archiveModel struct {
+	index      int
+	model      *common.Model
+	position   math32.Vector3
+	bounds     math32.Box3 // Bounding box where the model is shown
+	state      int
+	prepared   *mesh.Prepared
+	node       core.INode
+	rig        *graphic.RiggedMesh
+	anims      []*animation.Animation
+	size       int // Estimated GPU memory in bytes
+	lastViewed time.Time
+	after      []func() // Called once the model is shown
+}
+
+type preparedResult struct {
+	index    int
+	prepared *mesh.Prepared
+	err      error
+}
+
+// newArchiveLoader returns a loader for the models of q, its workers are started with prepare
+func newArchiveLoader(path string, q *quail.Quail, isEager bool) *archiveLoader {
+	return &archiveLoader{
+		path:       path,
+		q:          q,
+		jobs:       make(chan int, len(q.Models)),
+		result:     make(chan preparedResult, len(q.Models)),
+		isEager:    isEager,
+		placeables: make(map[string]*graphic.Mesh),
+	}
+}
+
+// loadArchive lists the models of q from their metadata and starts the workers preparing them,
+// returns the widest model size
+func (gv *g3nView) loadArchive(path string, q *quail.Quail) float64 {
+	l := newArchiveLoader(path, q, gv.isZone)
+	gv.loader = l
+
+	maxWidth := 3.0
+	for i, model := range q.Models {
+		am := &archiveModel{index: i, model: model}
+		if !gv.isZone {
+			am.position = math32.Vector3{Z: float32(float64(i) * 2.0)}
+		}
+		bounds := mesh.Bounds(model)
+		am.bounds = bounds
+		am.bounds.Translate(&am.position)
+		l.models = append(l.models, am)
+
+		if gv.isZone && zone.IsPlaceable(q.Zone, model.Header.Name) {
+			continue
+		}
+		meshWidth := float64(bounds.Max.X) * 2
+		if float64(bounds.Max.Y)*2 > meshWidth {
+			meshWidth = float64(bounds.Max.Y) * 2
+		}
+		if float64(bounds.Max.Z)*2 > meshWidth {
+			meshWidth = float64(bounds.Max.Z) * 2
+		}
+		if meshWidth > maxWidth {
+			maxWidth = meshWidth
+		}
+
+		if len(gv.focusModels) > i {
+			fm := gv.focusModels[i]
+			fm.index = i
+			fm.meshWidth = meshWidth
+			fm.mi.SetVisible(true)
+			fm.mi.SetText(model.Header.Name)
+		}
+	}
+
+	if l.isEager {
+		for _, am := range l.models {
+			l.request(am)
+		}
+	}
+	go l.prepare()
+	return maxWidth
+}
+
+// prepare reads the bone weights of the archive, then prepares requested models on a worker per CPU
+func (l *archiveLoader) prepare() {
+	weights, err := skin.ReadAll(l.path)
+	if err != nil {
+		fmt.Println("skin weights not loaded:", err)
+	}
+	l.weights = weights
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range l.jobs {
+				if atomic.LoadInt32(&l.canceled) == 1 {
+					continue
+				}
+				model := l.q.Models[i]
+				p, err := mesh.Prepare(l.q, model)
+				if err == nil && len(model.Bones) > 0 {
+					p.PrepareSkin(len(model.Bones), weights[strings.ToLower(model.Header.Name)])
+				}
+				l.result <- preparedResult{index: i, prepared: p, err: err}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// request queues am to be prepared if it isn't built or being built
+func (l *archiveLoader) request(am *archiveModel) {
+	if am.state != modelUnloaded {
+		return
+	}
+	// a closed loader has no workers left to prepare it
+	if atomic.LoadInt32(&l.canceled) == 1 {
+		return
+	}
+	am.state = modelPreparing
+	l.requested++
+	// jobs holds every model, a model is only queued again once evicted
+	l.jobs <- am.index
+}
+
+// close stops the workers, models being prepared are dropped
+func (l *archiveLoader) close() {
+	if atomic.LoadInt32(&l.canceled) == 1 {
+		return
+	}
+	atomic.StoreInt32(&l.canceled, 1)
+	close(l.jobs)
+	l.disposePlaceables()
+}
+
+// disposePlaceables releases the zone object templates, placed instances hold their own references
+func (l *archiveLoader) disposePlaceables() {
+	for name, template := range l.placeables {
+		template.Dispose()
+		delete(l.placeables, name)
+	}
+}
+
+// isIdle returns true when no requested model is waiting to be shown
+func (l *archiveLoader) isIdle() bool {
+	return l.shown >= l.requested
+}
+
+// model returns the archive model named name, or nil
+func (l *archiveLoader) model(name string) *archiveModel {
+	for _, am := range l.models {
+		if am.model.Header.Name == name {
+			return am
+		}
+	}
+	return nil
+}
+
+// updateArchiveLoad requests models in view, builds prepared models, evicts models over the
+// memory budget and shows loading progress, called each frame
+func (gv *g3nView) updateArchiveLoad() {
+	l := gv.loader
+	if l == nil {
+		return
+	}
+
+	if gv.receivePrepared(l) > 0 {
+		gv.archiveWeights = l.weights
+	}
+
+	now := time.Now()
+	if !l.isEager {
+		gv.requestVisible(l, now)
+	}
+
+	// at least one model is built each frame so a slow upload can't stall loading
+	start := time.Now()
+	for _, am := range l.models {
+		if am.state != modelPrepared {
+			continue
+		}
+		err := gv.addArchiveModel(l, am)
+		if err != nil {
+			am.state = modelFailed
+			gv.ed.Show(fmt.Sprintf("load %s: %s", am.model.Header.Name, err))
+		}
+		am.lastViewed = now
+		l.shown++
+		if time.Since(start) > loadFrameBudget {
+			break
+		}
+	}
+
+	if l.isEager && !l.isPlaced && l.isIdle() {
+		l.isPlaced = true
+		err := gv.placeZone(l)
+		if err != nil {
+			gv.ed.Show(fmt.Sprintf("load %s: %s", l.path, err))
+		}
+	}
+	if !l.isEager {
+		gv.evictModels(l, now, gv.evictArchiveModel)
+	}
+
+	if l.isIdle() {
+		l.requested = 0
+		l.shown = 0
+		if gv.loadBar.Visible() {
+			gv.loadBar.Show(false)
+		}
+		return
+	}
+	gv.loadBar.SetProgress(fmt.Sprintf("Loading models: %d/%d", l.shown, l.requested), float32(l.shown)/float32(l.requested))
+	if !gv.loadBar.Visible() {
+		gv.loadBar.Show(true)
+	}
+}
+
+// receivePrepared takes the models prepared by the workers of l without blocking,
+// returns how many were received
+func (gv *g3nView) receivePrepared(l *archiveLoader) int {
+	count := 0
+	for {
+		select {
+		case r := <-l.result:
+			count++
+			am := l.models[r.index]
+			if r.err != nil {
+				am.state = modelFailed
+				l.shown++
+				gv.ed.Show(fmt.Sprintf("generate %s: %s", am.model.Header.Name, r.err))
+				continue
+			}
+			am.prepared = r.prepared
+			am.state = modelPrepared
+		default:
+			return count
+		}
+	}
+}
+
+// requestVisible requests the models in view of the camera and the focused model
+func (gv *g3nView) requestVisible(l *archiveLoader, now time.Time) {
+	cam := gv.activeCam()
+	var proj, view, projView math32.Matrix4
+	cam.ProjMatrix(&proj)
+	cam.ViewMatrix(&view)
+	projView.MultiplyMatrices(&proj, &view)
+	frustum := math32.NewFrustumFromMatrix(&projView)
+
+	for _, am := range l.models {
+		if am.model.Header.Name != gv.focusName && !frustum.IntersectsBox(&am.bounds) {
+			continue
+		}
+		am.lastViewed = now
+		l.request(am)
+	}
+}
+
+// evictModels disposes the models viewed longest ago with evict until the shown models fit the
+// memory budget. Models in view and the focused model are kept
+func (gv *g3nView) evictModels(l *archiveLoader, now time.Time, evict func(am *archiveModel)) {
+	budget := gv.settings.ModelBudget * 1024 * 1024
+	total := 0
+	candidates := []*archiveModel{}
+	for _, am := range l.models {
+		if am.state != modelShown {
+			continue
+		}
+		total += am.size
+		if am.lastViewed.Before(now) {
+			candidates = append(candidates, am)
+		}
+	}
+	if total <= budget {
+		return
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastViewed.Before(candidates[j].lastViewed)
+	})
+	for _, am := range candidates {
+		if total <= budget {
+			break
+		}
+		total -= am.size
+		evict(am)
+	}
+}
+
+// addArchiveModel builds the prepared model am and adds it to the scene
+func (gv *g3nView) addArchiveModel(l *archiveLoader, am *archiveModel) error {
+	var meshInstance core.INode
+	model := am.model
+	modelMesh := am.prepared.Build()
+	am.size = am.prepared.Size()
+	am.prepared = nil
+	am.state = modelShown
+
+	if l.isEager && zone.IsPlaceable(l.q.Zone, model.Header.Name) {
+		l.placeables[model.Header.Name] = modelMesh
+		return nil
+	}
+
+	modelMesh.SetPositionVec(&am.position)
+	meshInstance = modelMesh
+
+	if len(model.Bones) > 0 {
+		skel, err := skeleton.Generate(model.Bones)
+		if err != nil {
+			return fmt.Errorf("generate skeleton: %w", err)
+		}
+
+		rigMesh := graphic.NewRiggedMesh(modelMesh)
+		rigMesh.SetSkeleton(skel)
+		skeleton.Attach(skel, rigMesh)
+		meshInstance = rigMesh
+		am.rig = rigMesh
+	}
+
+	gv.scene.Add(meshInstance)
+	node := meshInstance.GetNode()
+	node.SetName(model.Header.Name)
+	node.SetUserData(model.Header.Name)
+	gv.archiveNodes = append(gv.archiveNodes, meshInstance)
+	am.node = meshInstance
+
+	if len(gv.focusModels) > am.index {
+		gv.focusModels[am.index].node = node
+	}
+
+	if am.rig != nil {
+		anims, err := gv.modelAnims(am)
+		if err != nil {
+			return err
+		}
+		am.anims = anims
+		gv.anims = append(gv.anims, anims...)
+		if gv.animName == "" && len(anims) > 0 {
+			gv.animName = anims[0].Name()
+		}
+		gv.refreshAnimMenu()
+	}
+
+	for _, fn := range am.after {
+		fn()
+	}
+	am.after = nil
+	return nil
+}
+
+// evictArchiveModel removes the scene node of am and frees its GPU resources,
+// it's built again the next time it's in view
+func (gv *g3nView) evictArchiveModel(am *archiveModel) {
+	if gv.highlight != nil && gv.highlight.Parent() != nil && gv.highlight.Parent().GetNode() == am.node.GetNode() {
+		gv.clearHighlight()
+	}
+	gv.scene.Remove(am.node)
+	am.node.Dispose()
+	for i, node := range gv.archiveNodes {
+		if node == am.node {
+			gv.archiveNodes = append(gv.archiveNodes[:i], gv.archiveNodes[i+1:]...)
+			break
+		}
+	}
+	if len(gv.focusModels) > am.index {
+		gv.focusModels[am.index].node = nil
+	}
+
+	if len(am.anims) > 0 {
+		anims := []*animation.Animation{}
+		for _, a := range gv.anims {
+			isEvicted := false
+			for _, evicted := range am.anims {
+				if a == evicted {
+					isEvicted = true
+					break
+				}
+			}
+			if !isEvicted {
+				anims = append(anims, a)
+			}
+		}
+		gv.anims = anims
+		gv.refreshAnimMenu()
+	}
+
+	am.node = nil
+	am.rig = nil
+	am.anims = nil
+	am.size = 0
+	am.state = modelUnloaded
+	fmt.Println("Evicted", am.model.Header.Name)
+}
+
+// placeZone instances the zone objects once every placeable model is built
+func (gv *g3nView) placeZone(l *archiveLoader) error {
+	zoneNode, err := zone.Generate(l.q.Zone, l.placeables)
+	l.disposePlaceables()
+	if err != nil {
+		return fmt.Errorf("generate zone: %w", err)
+	}
+	gv.scene.Add(zoneNode)
+	gv.archiveNodes = append(gv.archiveNodes, zoneNode)
+	fmt.Println("zone objects placed:", len(zoneNode.Children()))
+	return nil
+}
+
+// modelAnims generates the archive and borrowed animations of a rigged model
+func (gv *g3nView) modelAnims(am *archiveModel) ([]*animation.Animation, error) {
+	anims, err := anim.Generate(gv.archiveQuail.Animations, []*graphic.RiggedMesh{am.rig})
+	if err != nil {
+		return nil, fmt.Errorf("generate anim: %w", err)
+	}
+	for _, source := range gv.animSources {
+		if source.model != "" && source.model != am.model.Header.Name {
+			continue
+		}
+		borrowed, err := sourceAnims(source, am.rig)
+		if err != nil {
+			return nil, err
+		}
+		anims = append(anims, borrowed...)
+	}
+	return anims, nil
+}
+
+// whenModelShown calls fn once the archive model named name is shown, building it if needed.
+// fn is called now if the model is shown or isn't in the archive
+func (gv *g3nView) whenModelShown(name string, fn func()) {
+	if gv.loader == nil {
+		fn()
+		return
+	}
+	am := gv.loader.model(name)
+	if am == nil || am.state == modelShown || am.state == modelFailed {
+		fn()
+		return
+	}
+	am.after = append(am.after, fn)
+	gv.loader.request(am)
+}
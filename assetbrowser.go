@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/xackery/engine/app"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/gui/assets/icon"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/engine/window"
+	"github.com/xackery/quail-view/assets"
+	"github.com/xackery/quail-view/settings"
+)
+
+// assetResultMax caps how many search results are listed
+const assetResultMax = 200
+
+type AssetBrowser struct {
+	gui.Panel
+	dir     *gui.Edit
+	search  *gui.Edit
+	status  *gui.Label
+	list    *gui.List
+	index   *assets.Index
+	matches []*assets.Match
+	bindex  *gui.Button
+	bopen   *gui.Button
+	bclose  *gui.Button
+}
+
+func NewAssetBrowser(width, height float32) *AssetBrowser {
+
+	ab := new(AssetBrowser)
+	ab.Panel.Initialize(ab, width, height)
+	ab.SetBorders(2, 2, 2, 2)
+	ab.SetPaddings(4, 4, 4, 4)
+	ab.SetColor(math32.NewColor("White"))
+	ab.SetVisible(false)
+	ab.SetBounded(false)
+
+	// Set vertical box layout for the whole panel
+	l := gui.NewVBoxLayout()
+	l.SetSpacing(4)
+	ab.SetLayout(l)
+
+	ab.Add(gui.NewLabel("EQ directory"))
+	ab.dir = gui.NewEdit(int(width)-16, "path to EQ client")
+	ab.dir.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 0, AlignH: gui.AlignWidth})
+	ab.dir.Subscribe(gui.OnKeyDown, func(evname string, ev interface{}) {
+		kev := ev.(*window.KeyEvent)
+		if kev.Key == window.KeyEnter || kev.Key == window.KeyKPEnter {
+			ab.Dispatch("OnIndex", ab.dir.Text())
+		}
+	})
+	ab.Add(ab.dir)
+
+	ab.search = gui.NewEdit(int(width)-16, "search, e.g. orc or model:orc")
+	ab.search.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 0, AlignH: gui.AlignWidth})
+	ab.search.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		ab.refresh()
+	})
+	ab.Add(ab.search)
+
+	ab.status = gui.NewLabel("No index")
+	ab.Add(ab.status)
+
+	ab.list = gui.NewVList(0, 0)
+	ab.list.SetSingle(true)
+	ab.list.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 5, AlignH: gui.AlignWidth})
+	ab.Add(ab.list)
+
+	// Button container panel
+	bc := gui.NewPanel(0, 0)
+	bcl := gui.NewHBoxLayout()
+	bcl.SetAlignH(gui.AlignWidth)
+	bc.SetLayout(bcl)
+	bc.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 1, AlignH: gui.AlignWidth})
+	ab.Add(bc)
+
+	// Creates Index button
+	ab.bindex = gui.NewButton("Index")
+	ab.bindex.SetLayoutParams(&gui.HBoxLayoutParams{Expand: 0, AlignV: gui.AlignCenter})
+	ab.bindex.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		ab.Dispatch("OnIndex", ab.dir.Text())
+	})
+	bc.Add(ab.bindex)
+
+	// Creates Open button
+	ab.bopen = gui.NewButton("Open")
+	ab.bopen.SetLayoutParams(&gui.HBoxLayoutParams{Expand: 0, AlignV: gui.AlignCenter})
+	ab.bopen.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		match := ab.Selected()
+		if match == nil {
+			return
+		}
+		ab.Dispatch("OnOpen", match)
+	})
+	bc.Add(ab.bopen)
+
+	// Creates Close button
+	ab.bclose = gui.NewButton("Close")
+	ab.bclose.SetLayoutParams(&gui.HBoxLayoutParams{Expand: 0, AlignV: gui.AlignCenter})
+	ab.bclose.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		ab.SetVisible(false)
+	})
+	bc.Add(ab.bclose)
+
+	return ab
+}
+
+// Show shows or hide the asset browser
+func (ab *AssetBrowser) Show(show bool) {
+
+	if !show {
+		ab.SetVisible(false)
+		return
+	}
+	ab.SetVisible(true)
+	_, height := app.App(300, 300, "Assets").GetSize()
+	ab.SetPosition(10, (float32(height)-ab.Height())/2)
+}
+
+// SetDir sets the EQ directory shown in the directory box
+func (ab *AssetBrowser) SetDir(dir string) {
+	ab.dir.SetText(dir)
+}
+
+// SetStatus sets the status line text
+func (ab *AssetBrowser) SetStatus(text string) {
+	ab.status.SetText(text)
+}
+
+// SetIndex sets the index searched and refreshes the results
+func (ab *AssetBrowser) SetIndex(idx *assets.Index) {
+	ab.index = idx
+	ab.refresh()
+}
+
+// Selected returns the picked search result
+func (ab *AssetBrowser) Selected() *assets.Match {
+	sel := ab.list.Selected()
+	if len(sel) == 0 {
+		return nil
+	}
+	pos := ab.list.ItemPosition(sel[0])
+	if pos < 0 || pos >= len(ab.matches) {
+		return nil
+	}
+	return ab.matches[pos]
+}
+
+// refresh lists the assets matching the search box
+func (ab *AssetBrowser) refresh() {
+	ab.list.Clear()
+	ab.matches = nil
+	if ab.index == nil {
+		ab.status.SetText("No index")
+		return
+	}
+
+	ab.matches = ab.index.Search(ab.search.Text())
+	total := len(ab.matches)
+	if len(ab.matches) > assetResultMax {
+		ab.matches = ab.matches[:assetResultMax]
+	}
+	for _, match := range ab.matches {
+		item := gui.NewImageLabel(fmt.Sprintf("%s: %s", filepath.Base(match.Archive.Path), match.Name))
+		switch match.Kind {
+		case assets.KindModel:
+			item.SetIcon(icon.Layers)
+		case assets.KindTexture:
+			item.SetIcon(icon.InsertPhoto)
+		case assets.KindAnimation:
+			item.SetIcon(icon.DirectionsRun)
+		}
+		ab.list.Add(item)
+	}
+	ab.status.SetText(fmt.Sprintf("%d matches in %d archives", total, len(ab.index.Archives)))
+}
+
+// assetIndexer builds an asset index in the background, the render loop collects the result
+type assetIndexer struct {
+	done    int32
+	total   int32
+	running int32
+	result  chan *assets.Index
+}
+
+// buildAssetBrowser creates the asset browser panel
+func (gv *g3nView) buildAssetBrowser() {
+	gv.ab = NewAssetBrowser(360, 420)
+	gv.ab.SetDir(gv.settings.EQPath)
+	gv.ab.Subscribe("OnIndex", func(evname string, ev interface{}) {
+		dir := ev.(string)
+		if dir == "" {
+			gv.ed.Show("EQ directory not set")
+			return
+		}
+		gv.settings.EQPath = dir
+		gv.saveSettings()
+		gv.indexAssets(dir)
+	})
+	gv.ab.Subscribe("OnOpen", func(evname string, ev interface{}) {
+		err := gv.openAsset(ev.(*assets.Match))
+		if err != nil {
+			gv.ed.Show(err.Error())
+		}
+	})
+	gv.scene.Add(gv.ab)
+	gv.indexer = &assetIndexer{result: make(chan *assets.Index, 1)}
+}
+
+// showAssetBrowser opens the asset browser, loading the saved index on first use
+func (gv *g3nView) showAssetBrowser() {
+	gv.ab.Show(true)
+	if gv.ab.index != nil || gv.settings.EQPath == "" {
+		return
+	}
+	path, err := assetIndexPath()
+	if err != nil {
+		fmt.Println("Failed to find asset index:", err)
+		return
+	}
+	idx, err := assets.Load(path)
+	if err != nil {
+		fmt.Println("Failed to load asset index:", err)
+	}
+	if idx == nil || idx.Dir != gv.settings.EQPath {
+		gv.indexAssets(gv.settings.EQPath)
+		return
+	}
+	gv.ab.SetIndex(idx)
+}
+
+// indexAssets starts indexing dir in the background
+func (gv *g3nView) indexAssets(dir string) {
+	ix := gv.indexer
+	if !atomic.CompareAndSwapInt32(&ix.running, 0, 1) {
+		return
+	}
+	atomic.StoreInt32(&ix.done, 0)
+	atomic.StoreInt32(&ix.total, 0)
+	cache := gv.ab.index
+	go func() {
+		defer atomic.StoreInt32(&ix.running, 0)
+		idx, err := assets.Build(dir, cache, func(done int, total int) {
+			atomic.StoreInt32(&ix.done, int32(done))
+			atomic.StoreInt32(&ix.total, int32(total))
+		})
+		if err != nil {
+			fmt.Println("Failed to index assets:", err)
+			ix.result <- nil
+			return
+		}
+		path, err := assetIndexPath()
+		if err == nil {
+			err = idx.Save(path)
+		}
+		if err != nil {
+			fmt.Println("Failed to save asset index:", err)
+		}
+		ix.result <- idx
+	}()
+}
+
+// updateAssetIndex shows indexing progress and applies a finished index, called each frame
+func (gv *g3nView) updateAssetIndex() {
+	ix := gv.indexer
+	select {
+	case idx := <-ix.result:
+		if idx == nil {
+			gv.ab.SetStatus("Indexing failed")
+			return
+		}
+		gv.ab.SetIndex(idx)
+	default:
+		if atomic.LoadInt32(&ix.running) == 1 {
+			gv.ab.SetStatus(fmt.Sprintf("Indexing %d/%d", atomic.LoadInt32(&ix.done), atomic.LoadInt32(&ix.total)))
+		}
+	}
+}
+
+// openAsset opens the archive of match and shows the matched asset
+func (gv *g3nView) openAsset(match *assets.Match) error {
+	if match.Archive.Path != gv.archivePath {
+		err := gv.openModel(match.Archive.Path)
+		if err != nil {
+			return err
+		}
+	}
+	switch match.Kind {
+	case assets.KindModel:
+		// the model may not be built yet, it's focused once shown
+		gv.whenModelShown(match.Name, func() {
+			for _, fm := range gv.focusModels {
+				if fm.node != nil && fm.node.Name() == match.Name {
+					fm.onClick("", nil)
+					return
+				}
+			}
+			fmt.Println("Model", match.Name, "is not in the Center On menu")
+		})
+	case assets.KindAnimation:
+		gv.setAnimation(match.Name)
+	}
+	return nil
+}
+
+// assetIndexPath returns where the asset index is cached
+func assetIndexPath() (string, error) {
+	dir, err := settings.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "assets.json"), nil
+}
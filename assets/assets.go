@@ -0,0 +1,188 @@
+// Package assets indexes the archives of an EQ client directory so their contents can be searched
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xackery/quail/quail"
+)
+
+const (
+	KindModel     = "model"
+	KindTexture   = "texture"
+	KindAnimation = "animation"
+)
+
+// Archive lists the assets stored in one .s3d or .eqg file
+type Archive struct {
+	Path       string    `json:"path"`
+	ModTime    time.Time `json:"mod_time"`
+	Models     []string  `json:"models"`
+	Textures   []string  `json:"textures"`
+	Animations []string  `json:"animations"`
+}
+
+// Index is every archive found in an EQ directory
+type Index struct {
+	Dir      string     `json:"dir"`
+	Archives []*Archive `json:"archives"`
+}
+
+// Match is an asset found by Search
+type Match struct {
+	Archive *Archive
+	Kind    string
+	Name    string
+}
+
+// IsArchive returns true if path has an EQ archive extension
+func IsArchive(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".s3d" || ext == ".eqg"
+}
+
+// Build indexes every archive in dir. Archives in cache that were not modified are reused,
+// progress is called after each archive
+func Build(dir string, cache *Index, progress func(done int, total int)) (*Index, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+
+	cached := make(map[string]*Archive)
+	if cache != nil && cache.Dir == dir {
+		for _, a := range cache.Archives {
+			cached[a.Path] = a
+		}
+	}
+
+	paths := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !IsArchive(entry.Name()) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+
+	idx := &Index{Dir: dir}
+	for i, path := range paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("stat: %w", err)
+		}
+		a, ok := cached[path]
+		if !ok || !a.ModTime.Equal(fi.ModTime()) {
+			a, err = ReadArchive(path)
+			if err != nil {
+				fmt.Printf("Failed to index %s: %s\n", filepath.Base(path), err)
+				a = &Archive{Path: path}
+			}
+			a.ModTime = fi.ModTime()
+		}
+		idx.Archives = append(idx.Archives, a)
+		if progress != nil {
+			progress(i+1, len(paths))
+		}
+	}
+	return idx, nil
+}
+
+// ReadArchive lists the models, textures and animations inside an archive
+func ReadArchive(path string) (*Archive, error) {
+	q := quail.New()
+	err := q.PfsRead(path)
+	if err != nil {
+		return nil, fmt.Errorf("pfs read: %w", err)
+	}
+	defer q.Close()
+
+	a := &Archive{Path: path}
+	for _, model := range q.Models {
+		a.Models = append(a.Models, model.Header.Name)
+	}
+	for name := range q.Textures {
+		a.Textures = append(a.Textures, name)
+	}
+	for _, anim := range q.Animations {
+		a.Animations = append(a.Animations, anim.Header.Name)
+	}
+	sort.Strings(a.Models)
+	sort.Strings(a.Textures)
+	sort.Strings(a.Animations)
+	return a, nil
+}
+
+// Search returns assets whose name contains query, ignoring case.
+// A query may be prefixed with a kind, such as "model:orc"
+func (idx *Index) Search(query string) []*Match {
+	kind := ""
+	query = strings.ToLower(strings.TrimSpace(query))
+	for _, k := range []string{KindModel, KindTexture, KindAnimation} {
+		if strings.HasPrefix(query, k+":") {
+			kind = k
+			query = strings.TrimSpace(strings.TrimPrefix(query, k+":"))
+			break
+		}
+	}
+
+	matches := []*Match{}
+	for _, a := range idx.Archives {
+		groups := map[string][]string{
+			KindModel:     a.Models,
+			KindTexture:   a.Textures,
+			KindAnimation: a.Animations,
+		}
+		for _, k := range []string{KindModel, KindTexture, KindAnimation} {
+			if kind != "" && kind != k {
+				continue
+			}
+			for _, name := range groups[k] {
+				if !strings.Contains(strings.ToLower(name), query) {
+					continue
+				}
+				matches = append(matches, &Match{Archive: a, Kind: k, Name: name})
+			}
+		}
+	}
+	return matches
+}
+
+// Load reads an index saved by Save, returning nil if path does not exist
+func Load(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read: %w", err)
+	}
+	idx := &Index{}
+	err = json.Unmarshal(data, idx)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return idx, nil
+}
+
+// Save writes the index to path
+func (idx *Index) Save(path string) error {
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package assets
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIndexSearch(t *testing.T) {
+	idx := &Index{
+		Archives: []*Archive{
+			{Path: "gequip.s3d", Models: []string{"it1"}, Textures: []string{"orcshield.bmp"}},
+			{Path: "orc_chr.s3d", Models: []string{"ORC_HS_DEF"}, Animations: []string{"c01orc"}},
+			{Path: "globalelf_chr.s3d", Models: []string{"ELF_HS_DEF"}},
+		},
+	}
+
+	matches := idx.Search("orc")
+	if len(matches) != 3 {
+		t.Fatalf("expected 3 matches, got %d", len(matches))
+	}
+
+	matches = idx.Search("model:Orc")
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 model match, got %d", len(matches))
+	}
+	if matches[0].Archive.Path != "orc_chr.s3d" || matches[0].Kind != KindModel {
+		t.Fatalf("unexpected match %+v", matches[0])
+	}
+}
+
+func TestIndexSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "assets.json")
+	idx, err := Load(path)
+	if err != nil {
+		t.Fatalf("load missing: %s", err.Error())
+	}
+	if idx != nil {
+		t.Fatalf("expected nil index for missing file")
+	}
+
+	idx = &Index{Dir: "eq", Archives: []*Archive{{Path: "eq/orc_chr.s3d", Models: []string{"ORC_HS_DEF"}}}}
+	err = idx.Save(path)
+	if err != nil {
+		t.Fatalf("save: %s", err.Error())
+	}
+	idx, err = Load(path)
+	if err != nil {
+		t.Fatalf("load: %s", err.Error())
+	}
+	if idx.Dir != "eq" || len(idx.Archives) != 1 || idx.Archives[0].Models[0] != "ORC_HS_DEF" {
+		t.Fatalf("unexpected index %+v", idx)
+	}
+}
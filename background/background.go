@@ -0,0 +1,97 @@
+package background
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/geometry"
+	"github.com/xackery/engine/gls"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/engine/light"
+	"github.com/xackery/engine/material"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/mesh"
+	"github.com/xackery/quail-view/settings"
+	"github.com/xackery/quail/quail"
+)
+
+// Generate creates the background node for the mode of s, or nil for a solid color
+func Generate(s *settings.Background) (*core.Node, error) {
+	switch s.Mode {
+	case settings.BackgroundSolid:
+		return nil, nil
+	case settings.BackgroundGradient:
+		node := core.NewNode()
+		node.Add(NewGradient(s.Top, s.Bottom))
+		return node, nil
+	case settings.BackgroundSkybox:
+		return nil, fmt.Errorf("skybox backgrounds are prepared with PrepareSky")
+	default:
+		return nil, fmt.Errorf("unknown background mode %s", s.Mode)
+	}
+}
+
+// NewGradient creates an inward facing dome shaded from top to bottom color
+func NewGradient(top [3]float32, bottom [3]float32) *graphic.Mesh {
+	geom := geometry.NewSphere(100, 32, 16)
+
+	colors := math32.NewArrayF32(0, 16)
+	geom.ReadVertices(func(vertex math32.Vector3) bool {
+		t := (vertex.Y/100 + 1) / 2
+		colors.Append(
+			bottom[0]+(top[0]-bottom[0])*t,
+			bottom[1]+(top[1]-bottom[1])*t,
+			bottom[2]+(top[2]-bottom[2])*t,
+		)
+		return false
+	})
+	geom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+
+	mat := material.NewBasic()
+	mat.SetSide(material.SideBack)
+	mat.SetDepthMask(false)
+	return graphic.NewMesh(geom, mat)
+}
+
+// PrepareSky reads and decodes a sky model from an EQ sky archive such as sky.s3d, it does
+// no GL calls and may run on any goroutine. The first model is used if modelName is empty
+// or not found
+func PrepareSky(path string, modelName string) (*mesh.Prepared, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sky archive not set")
+	}
+	q := &quail.Quail{}
+	err := q.PfsRead(path)
+	if err != nil {
+		return nil, fmt.Errorf("pfs read %s: %w", path, err)
+	}
+	if len(q.Models) == 0 {
+		return nil, fmt.Errorf("no sky models in %s", filepath.Base(path))
+	}
+
+	model := q.Models[0]
+	for _, m := range q.Models {
+		if strings.EqualFold(m.Header.Name, modelName) {
+			model = m
+			break
+		}
+	}
+
+	p, err := mesh.Prepare(q, model)
+	if err != nil {
+		return nil, fmt.Errorf("prepare %s: %w", model.Header.Name, err)
+	}
+	return p, nil
+}
+
+// BuildSky uploads a prepared sky, it must be called from the render thread
+func BuildSky(p *mesh.Prepared) *core.Node {
+	node := core.NewNode()
+	node.SetName(p.Model.Header.Name)
+	node.Add(p.Build())
+	// the background pass has no scene lights, sky textures are shown unlit
+	node.Add(light.NewAmbient(&math32.Color{R: 1, G: 1, B: 1}, 1))
+	return node
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/quail-view/background"
+	"github.com/xackery/quail-view/mesh"
+	"github.com/xackery/quail-view/settings"
+)
+
+type backgroundPreset struct {
+	name  string
+	mode  string
+	color [3]float32
+	top   [3]float32
+}
+
+// backgroundPresets are the background choices listed in the view menu
+var backgroundPresets = []*backgroundPreset{
+	{name: "Dark gray", mode: settings.BackgroundSolid, color: [3]float32{0.2, 0.2, 0.2}},
+	{name: "Black", mode: settings.BackgroundSolid, color: [3]float32{0, 0, 0}},
+	{name: "Light gray", mode: settings.BackgroundSolid, color: [3]float32{0.8, 0.8, 0.8}},
+	{name: "White", mode: settings.BackgroundSolid, color: [3]float32{1, 1, 1}},
+	{name: "Dark gradient", mode: settings.BackgroundGradient, color: [3]float32{0.1, 0.1, 0.1}, top: [3]float32{0.35, 0.4, 0.5}},
+	{name: "Light gradient", mode: settings.BackgroundGradient, color: [3]float32{0.6, 0.6, 0.6}, top: [3]float32{0.95, 0.95, 1}},
+	{name: "EQ sky", mode: settings.BackgroundSkybox},
+}
+
+// buildBackgroundMenu adds the background submenu to the view menu
+func (gv *g3nView) buildBackgroundMenu(m *gui.Menu) {
+	bgMenu := gui.NewMenu()
+	for _, preset := range backgroundPresets {
+		preset := preset
+		bgMenu.AddOption(preset.name).Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+			bg := *gv.settings.Background
+			bg.Mode = preset.mode
+			switch preset.mode {
+			case settings.BackgroundSolid:
+				bg.Color = preset.color
+			case settings.BackgroundGradient:
+				bg.Color = preset.color
+				bg.Bottom = preset.color
+				bg.Top = preset.top
+			case settings.BackgroundSkybox:
+				if bg.SkyPath == "" && gv.settings.EQPath != "" {
+					bg.SkyPath = filepath.Join(gv.settings.EQPath, "sky.s3d")
+				}
+			}
+			err := gv.setBackground(&bg)
+			if err != nil {
+				gv.ed.Show(err.Error())
+			}
+		})
+	}
+	m.AddMenu("Background", bgMenu)
+}
+
+// skyResult is a sky prepared on a worker goroutine
+type skyResult struct {
+	id        int
+	bg        *settings.Background
+	prepared  *mesh.Prepared
+	err       error
+	isRestore bool
+}
+
+// loadBackground restores the background from the previous session, a sky is shown once prepared
+func (gv *g3nView) loadBackground() {
+	gv.skyResult = make(chan skyResult, 1)
+	bg := gv.settings.Background
+	gv.Gls().ClearColor(bg.Color[0], bg.Color[1], bg.Color[2], 1)
+	if bg.Mode == settings.BackgroundSkybox {
+		gv.loadSky(bg, true)
+		return
+	}
+	node, err := background.Generate(bg)
+	if err != nil {
+		fmt.Println("Failed to restore background:", err)
+		gv.showBackground(settings.DefaultBackground(), nil)
+		return
+	}
+	gv.showBackground(bg, node)
+}
+
+// setBackground applies and persists background settings. A sky is applied once prepared
+func (gv *g3nView) setBackground(bg *settings.Background) error {
+	if bg.Mode == settings.BackgroundSkybox {
+		gv.loadSky(bg, false)
+		return nil
+	}
+	// a sky still loading is dropped
+	gv.skyLoad++
+	node, err := background.Generate(bg)
+	if err != nil {
+		return fmt.Errorf("background: %w", err)
+	}
+	gv.showBackground(bg, node)
+	gv.saveSettings()
+	return nil
+}
+
+// loadSky prepares the sky of bg on a goroutine, updateBackground shows it. A sky restored
+// from the previous session falls back to the default background if it fails
+func (gv *g3nView) loadSky(bg *settings.Background, isRestore bool) {
+	gv.skyLoad++
+	id := gv.skyLoad
+	go func() {
+		p, err := background.PrepareSky(bg.SkyPath, bg.SkyModel)
+		gv.skyResult <- skyResult{id: id, bg: bg, prepared: p, err: err, isRestore: isRestore}
+	}()
+}
+
+// updateBackground builds a prepared sky and shows it, called each frame
+func (gv *g3nView) updateBackground() {
+	select {
+	case r := <-gv.skyResult:
+		if r.id != gv.skyLoad {
+			return
+		}
+		if r.err != nil && r.isRestore {
+			fmt.Println("Failed to restore background:", r.err)
+			gv.showBackground(settings.DefaultBackground(), nil)
+			return
+		}
+		if r.err != nil {
+			gv.ed.Show(fmt.Sprintf("background: %s", r.err))
+			return
+		}
+		gv.showBackground(r.bg, background.BuildSky(r.prepared))
+		if !r.isRestore {
+			gv.saveSettings()
+		}
+	default:
+	}
+}
+
+// showBackground replaces the drawn background with node and sets the clear color of bg
+func (gv *g3nView) showBackground(bg *settings.Background, node *core.Node) {
+	if gv.bgScene != nil {
+		gv.bgScene.DisposeChildren(true)
+	}
+	gv.bgScene = node
+	gv.settings.Background = bg
+	gv.Gls().ClearColor(bg.Color[0], bg.Color[1], bg.Color[2], 1)
+}
+
+// renderBackground draws the gradient or sky pass centered on the active camera
+func (gv *g3nView) renderBackground(render func(scene core.INode) error) {
+	if gv.bgScene == nil {
+		return
+	}
+	pos := gv.activeCam().Position()
+	gv.bgScene.SetPositionVec(&pos)
+	err := render(gv.bgScene)
+	if err != nil {
+		fmt.Println("Failed to render background:", err)
+	}
+}
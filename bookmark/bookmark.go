@@ -0,0 +1,92 @@
+package bookmark
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Bookmark is a named camera pose
+type Bookmark struct {
+	Name       string     `json:"name"`
+	Position   [3]float32 `json:"position"`
+	Target     [3]float32 `json:"target"`
+	Up         [3]float32 `json:"up"`
+	Projection int        `json:"projection"`
+	Fov        float32    `json:"fov"`
+	Size       float32    `json:"size"`
+}
+
+// Store holds the bookmarks of an archive, keyed by model name
+type Store struct {
+	Archive string                 `json:"archive"`
+	Models  map[string][]*Bookmark `json:"models"`
+	path    string
+}
+
+// Path returns the sidecar file path used for an archive
+func Path(archivePath string) string {
+	return archivePath + ".bookmarks.json"
+}
+
+// Load reads the sidecar file of an archive, returning an empty store if it does not exist.
+// On error the empty store is returned too
+func Load(archivePath string) (*Store, error) {
+	s := &Store{
+		Archive: archivePath,
+		Models:  make(map[string][]*Bookmark),
+		path:    Path(archivePath),
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("read: %w", err)
+	}
+	err = json.Unmarshal(data, s)
+	if err != nil {
+		s.Models = make(map[string][]*Bookmark)
+		return s, fmt.Errorf("unmarshal %s: %w", s.path, err)
+	}
+	if s.Models == nil {
+		s.Models = make(map[string][]*Bookmark)
+	}
+	s.Archive = archivePath
+	return s, nil
+}
+
+// Save writes the store to its sidecar file
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	err = os.WriteFile(s.path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+// Bookmarks returns the bookmarks saved for a model, sorted by name
+func (s *Store) Bookmarks(model string) []*Bookmark {
+	bms := append([]*Bookmark{}, s.Models[model]...)
+	sort.Slice(bms, func(i, j int) bool {
+		return bms[i].Name < bms[j].Name
+	})
+	return bms
+}
+
+// Set adds a bookmark to a model, replacing any bookmark with the same name
+func (s *Store) Set(model string, bm *Bookmark) {
+	for i, existing := range s.Models[model] {
+		if existing.Name != bm.Name {
+			continue
+		}
+		s.Models[model][i] = bm
+		return
+	}
+	s.Models[model] = append(s.Models[model], bm)
+}
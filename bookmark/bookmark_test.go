@@ -0,0 +1,53 @@
+package bookmark
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreSaveLoad(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "it13968.eqg")
+	s, err := Load(archive)
+	if err != nil {
+		t.Fatalf("load missing: %s", err.Error())
+	}
+	if len(s.Models) != 0 {
+		t.Fatalf("expected an empty store, got %+v", s.Models)
+	}
+
+	s.Set("it13968", &Bookmark{Name: "side", Position: [3]float32{1, 2, 3}, Fov: 60})
+	s.Set("it13968", &Bookmark{Name: "front", Position: [3]float32{0, 0, 5}})
+	s.Set("it13968", &Bookmark{Name: "side", Position: [3]float32{4, 5, 6}})
+	err = s.Save()
+	if err != nil {
+		t.Fatalf("save: %s", err.Error())
+	}
+
+	s, err = Load(archive)
+	if err != nil {
+		t.Fatalf("load: %s", err.Error())
+	}
+	bms := s.Bookmarks("it13968")
+	if len(bms) != 2 || bms[0].Name != "front" || bms[1].Name != "side" {
+		t.Fatalf("bookmarks not restored, got %+v", bms)
+	}
+	if bms[1].Position != [3]float32{4, 5, 6} {
+		t.Fatalf("expected the replaced side bookmark, got %+v", bms[1])
+	}
+}
+
+func TestStoreLoadCorrupt(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "it13968.eqg")
+	err := os.WriteFile(Path(archive), []byte("{"), 0644)
+	if err != nil {
+		t.Fatalf("write: %s", err.Error())
+	}
+	s, err := Load(archive)
+	if err == nil {
+		t.Fatalf("expected an error loading a corrupt sidecar")
+	}
+	if s == nil || len(s.Bookmarks("it13968")) != 0 {
+		t.Fatalf("expected an empty store, got %+v", s)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/xackery/engine/camera"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/engine/window"
+	"github.com/xackery/quail-view/bookmark"
+)
+
+// bookmarkSlots is how many bookmarks are listed in the menu and bound to number keys
+const bookmarkSlots = 9
+
+type bookmarkEntry struct {
+	bm *bookmark.Bookmark
+	mi *gui.MenuItem
+}
+
+func (e *bookmarkEntry) onClick(evname string, ev interface{}) {
+	if e.bm == nil {
+		return
+	}
+	gv.applyBookmark(e.bm)
+}
+
+// loadBookmarks loads the bookmark sidecar of the opened archive. A sidecar that fails
+// to load leaves the archive without bookmarks
+func (gv *g3nView) loadBookmarks(archivePath string) error {
+	store, err := bookmark.Load(archivePath)
+	gv.bookmarks = store
+	gv.refreshBookmarkMenu()
+	if err != nil {
+		return fmt.Errorf("bookmark load: %w", err)
+	}
+	return nil
+}
+
+// buildBookmarkMenu creates the bookmarks menu and number key bindings
+func (gv *g3nView) buildBookmarkMenu(mb *gui.Menu) {
+	gv.bookmarkMenu = gui.NewMenu()
+	gv.bookmarkMenu.AddOption("Save bookmark").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		if gv.bookmarks == nil {
+			gv.ed.Show("No archive loaded")
+			return
+		}
+		gv.nd.Show("Bookmark name", fmt.Sprintf("Bookmark %d", len(gv.bookmarks.Bookmarks(gv.focusName))+1))
+	})
+	gv.bookmarkMenu.AddSeparator()
+	for i := 0; i < bookmarkSlots; i++ {
+		be := &bookmarkEntry{}
+		be.mi = gv.bookmarkMenu.AddOption(fmt.Sprintf("Bookmark %d", i+1))
+		be.mi.Subscribe(gui.OnClick, be.onClick)
+		be.mi.SetVisible(false)
+		gv.bookmarkEntries = append(gv.bookmarkEntries, be)
+	}
+	mb.AddMenu("Bookmarks", gv.bookmarkMenu)
+
+	// Creates bookmark name dialog
+	gv.nd = NewNameDialog(300, 100)
+	gv.nd.Subscribe("OnOK", func(evname string, ev interface{}) {
+		name := gv.nd.Name()
+		if name == "" {
+			gv.ed.Show("Bookmark name is empty")
+			return
+		}
+		err := gv.saveBookmark(name)
+		if err != nil {
+			gv.ed.Show(err.Error())
+			return
+		}
+		gv.nd.SetVisible(false)
+	})
+	gv.scene.Add(gv.nd)
+
+	// the gui manager only passes on keys while no edit or menu has key focus
+	gui.Manager().Subscribe(window.OnKeyDown, func(evname string, ev interface{}) {
+		kev := ev.(*window.KeyEvent)
+		if gv.nd.Visible() || kev.Mods != 0 {
+			return
+		}
+		if kev.Key < window.Key1 || kev.Key >= window.Key1+bookmarkSlots {
+			return
+		}
+		gv.bookmarkEntries[kev.Key-window.Key1].onClick("", nil)
+	})
+}
+
+// refreshBookmarkMenu lists the bookmarks of the focused model
+func (gv *g3nView) refreshBookmarkMenu() {
+	var bms []*bookmark.Bookmark
+	if gv.bookmarks != nil {
+		bms = gv.bookmarks.Bookmarks(gv.focusName)
+	}
+	for i, be := range gv.bookmarkEntries {
+		if i >= len(bms) {
+			be.bm = nil
+			be.mi.SetVisible(false)
+			continue
+		}
+		be.bm = bms[i]
+		be.mi.SetText(fmt.Sprintf("%s (%d)", bms[i].Name, i+1))
+		be.mi.SetVisible(true)
+	}
+}
+
+// saveBookmark stores the active camera pose under name for the focused model
+func (gv *g3nView) saveBookmark(name string) error {
+	bm := gv.cameraPose(name)
+	gv.bookmarks.Set(gv.focusName, bm)
+	err := gv.bookmarks.Save()
+	if err != nil {
+		return fmt.Errorf("bookmark save: %w", err)
+	}
+	gv.refreshBookmarkMenu()
+	fmt.Println("Saved bookmark", name)
+	return nil
+}
+
+// cameraPose returns the active camera pose as a bookmark
+func (gv *g3nView) cameraPose(name string) *bookmark.Bookmark {
+	cam := gv.activeCam()
+	pos := cam.Position()
+	target := gv.cameraTarget()
+	up := math32.Vector3{X: 0, Y: 1, Z: 0}
+	if gv.isFpsCamera {
+		_, up = gv.fly.GetDirections()
+	}
+
+	return &bookmark.Bookmark{
+		Name:       name,
+		Position:   [3]float32{pos.X, pos.Y, pos.Z},
+		Target:     [3]float32{target.X, target.Y, target.Z},
+		Up:         [3]float32{up.X, up.Y, up.Z},
+		Projection: int(cam.Projection()),
+		Fov:        cam.Fov(),
+		Size:       cam.Size(),
+	}
+}
+
+// applyBookmark moves the active camera to a bookmarked pose
+func (gv *g3nView) applyBookmark(bm *bookmark.Bookmark) {
+	pos := math32.Vector3{X: bm.Position[0], Y: bm.Position[1], Z: bm.Position[2]}
+	target := math32.Vector3{X: bm.Target[0], Y: bm.Target[1], Z: bm.Target[2]}
+	up := math32.Vector3{X: bm.Up[0], Y: bm.Up[1], Z: bm.Up[2]}
+	if up.Length() == 0 {
+		up = math32.Vector3{X: 0, Y: 1, Z: 0}
+	}
+
+	cam := gv.activeCam()
+	if gv.isFpsCamera {
+		cam.SetProjection(camera.Projection(bm.Projection))
+	} else {
+		gv.setOrthographic(camera.Projection(bm.Projection) == camera.Orthographic)
+	}
+	if bm.Fov > 0 {
+		cam.SetFov(bm.Fov)
+	}
+	if bm.Size > 0 {
+		cam.SetSize(bm.Size)
+	}
+	cam.SetPositionVec(&pos)
+	cam.LookAt(&target, &up)
+	if gv.isFpsCamera {
+		gv.fly.Reposition(&pos)
+		gv.fly.Reorient(&target, &up)
+	} else {
+		gv.orbit.SetTarget(target)
+	}
+	fmt.Println("Recalled bookmark", bm.Name)
+}
+
+// cameraTarget returns the point the active camera is looking at
+func (gv *g3nView) cameraTarget() math32.Vector3 {
+	if !gv.isFpsCamera {
+		return gv.orbit.Target()
+	}
+	pos := gv.fly.GetPosition()
+	forward, _ := gv.fly.GetDirections()
+	return *pos.Clone().Add(&forward)
+}
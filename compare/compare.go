@@ -0,0 +1,175 @@
+// Package compare reports differences between the contents of two archives
+package compare
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/xackery/quail/common"
+	"github.com/xackery/quail/quail"
+)
+
+const (
+	KindModel     = "model"
+	KindTexture   = "texture"
+	KindAnimation = "animation"
+
+	StatusAdded   = "added"
+	StatusRemoved = "removed"
+	StatusChanged = "changed"
+	StatusSame    = "same"
+)
+
+// Entry is the comparison result of one asset
+type Entry struct {
+	Kind   string
+	Name   string
+	Status string
+	Detail string
+}
+
+// String returns a one line summary of the entry
+func (e *Entry) String() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s %s %s", e.Status, e.Kind, e.Name)
+	}
+	return fmt.Sprintf("%s %s %s: %s", e.Status, e.Kind, e.Name, e.Detail)
+}
+
+// Report lists how archive b differs from archive a
+type Report struct {
+	Entries []*Entry
+}
+
+// Changes returns entries that are not the same in both archives
+func (r *Report) Changes() []*Entry {
+	changes := []*Entry{}
+	for _, e := range r.Entries {
+		if e.Status != StatusSame {
+			changes = append(changes, e)
+		}
+	}
+	return changes
+}
+
+// Count returns how many entries have status
+func (r *Report) Count(status string) int {
+	count := 0
+	for _, e := range r.Entries {
+		if e.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Diff compares the models, textures and animations of a and b
+func Diff(a, b *quail.Quail) *Report {
+	r := &Report{}
+
+	modelsA := make(map[string]*common.Model)
+	for _, m := range a.Models {
+		modelsA[m.Header.Name] = m
+	}
+	modelsB := make(map[string]*common.Model)
+	for _, m := range b.Models {
+		modelsB[m.Header.Name] = m
+	}
+	for _, name := range keys(modelsA, modelsB) {
+		ma, mb := modelsA[name], modelsB[name]
+		e := &Entry{Kind: KindModel, Name: name}
+		switch {
+		case mb == nil:
+			e.Status = StatusRemoved
+		case ma == nil:
+			e.Status = StatusAdded
+			e.Detail = fmt.Sprintf("%d verts, %d tris", len(mb.Vertices), len(mb.Triangles))
+		default:
+			e.Status, e.Detail = diffModel(ma, mb)
+		}
+		r.Entries = append(r.Entries, e)
+	}
+
+	for _, name := range keys(a.Textures, b.Textures) {
+		ta, okA := a.Textures[name]
+		tb, okB := b.Textures[name]
+		e := &Entry{Kind: KindTexture, Name: name, Status: StatusSame}
+		switch {
+		case !okB:
+			e.Status = StatusRemoved
+		case !okA:
+			e.Status = StatusAdded
+		case !bytes.Equal(ta, tb):
+			e.Status = StatusChanged
+			e.Detail = fmt.Sprintf("%d -> %d bytes", len(ta), len(tb))
+		}
+		r.Entries = append(r.Entries, e)
+	}
+
+	animsA := make(map[string]*common.Animation)
+	for _, anim := range a.Animations {
+		animsA[anim.Header.Name] = anim
+	}
+	animsB := make(map[string]*common.Animation)
+	for _, anim := range b.Animations {
+		animsB[anim.Header.Name] = anim
+	}
+	for _, name := range keys(animsA, animsB) {
+		aa, ab := animsA[name], animsB[name]
+		e := &Entry{Kind: KindAnimation, Name: name, Status: StatusSame}
+		switch {
+		case ab == nil:
+			e.Status = StatusRemoved
+		case aa == nil:
+			e.Status = StatusAdded
+		case !reflect.DeepEqual(aa.Bones, ab.Bones):
+			e.Status = StatusChanged
+			e.Detail = fmt.Sprintf("%d -> %d bones", len(aa.Bones), len(ab.Bones))
+		}
+		r.Entries = append(r.Entries, e)
+	}
+	return r
+}
+
+// diffModel compares two models with the same name
+func diffModel(a, b *common.Model) (string, string) {
+	details := []string{}
+	if len(a.Vertices) != len(b.Vertices) {
+		details = append(details, fmt.Sprintf("verts %d -> %d (%+d)", len(a.Vertices), len(b.Vertices), len(b.Vertices)-len(a.Vertices)))
+	}
+	if len(a.Triangles) != len(b.Triangles) {
+		details = append(details, fmt.Sprintf("tris %d -> %d (%+d)", len(a.Triangles), len(b.Triangles), len(b.Triangles)-len(a.Triangles)))
+	}
+	if len(a.Materials) != len(b.Materials) {
+		details = append(details, fmt.Sprintf("materials %d -> %d", len(a.Materials), len(b.Materials)))
+	}
+	if len(a.Bones) != len(b.Bones) {
+		details = append(details, fmt.Sprintf("bones %d -> %d", len(a.Bones), len(b.Bones)))
+	}
+	if len(details) > 0 {
+		return StatusChanged, strings.Join(details, ", ")
+	}
+	if !reflect.DeepEqual(a.Vertices, b.Vertices) || !reflect.DeepEqual(a.Triangles, b.Triangles) ||
+		!reflect.DeepEqual(a.Materials, b.Materials) || !reflect.DeepEqual(a.Bones, b.Bones) {
+		return StatusChanged, "data differs, counts match"
+	}
+	return StatusSame, ""
+}
+
+// keys returns the sorted union of the keys of a and b
+func keys[V any](a, b map[string]V) []string {
+	names := []string{}
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
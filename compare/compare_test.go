@@ -0,0 +1,35 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/xackery/quail/common"
+	"github.com/xackery/quail/quail"
+)
+
+func TestDiff(t *testing.T) {
+	a := &quail.Quail{
+		Models: []*common.Model{
+			{Header: &common.Header{Name: "orc"}, Vertices: make([]common.Vertex, 3), Triangles: make([]common.Triangle, 1)},
+			{Header: &common.Header{Name: "elf"}, Vertices: make([]common.Vertex, 3)},
+		},
+		Textures: map[string][]byte{"orc.dds": {1, 2}, "elf.dds": {1}},
+	}
+	b := &quail.Quail{
+		Models: []*common.Model{
+			{Header: &common.Header{Name: "orc"}, Vertices: make([]common.Vertex, 6), Triangles: make([]common.Triangle, 2)},
+			{Header: &common.Header{Name: "gnome"}, Vertices: make([]common.Vertex, 3)},
+		},
+		Textures: map[string][]byte{"orc.dds": {1, 3}, "elf.dds": {1}},
+	}
+
+	r := Diff(a, b)
+	if r.Count(StatusAdded) != 1 || r.Count(StatusRemoved) != 1 || r.Count(StatusChanged) != 2 || r.Count(StatusSame) != 1 {
+		t.Fatalf("unexpected report %v", r.Entries)
+	}
+	for _, e := range r.Changes() {
+		if e.Kind == KindModel && e.Name == "orc" && e.Detail != "verts 3 -> 6 (+3), tris 1 -> 2 (+1)" {
+			t.Fatalf("unexpected orc detail %q", e.Detail)
+		}
+	}
+}
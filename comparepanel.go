@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/xackery/engine/app"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/gui/assets/icon"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/compare"
+	"github.com/xackery/quail-view/mesh"
+	"github.com/xackery/quail/quail"
+)
+
+type ComparePanel struct {
+	gui.Panel
+	title   *gui.Label
+	summary *gui.Label
+	list    *gui.List
+	bclose  *gui.Button
+}
+
+func NewComparePanel(width, height float32) *ComparePanel {
+
+	cp := new(ComparePanel)
+	cp.Panel.Initialize(cp, width, height)
+	cp.SetBorders(2, 2, 2, 2)
+	cp.SetPaddings(4, 4, 4, 4)
+	cp.SetColor(math32.NewColor("White"))
+	cp.SetVisible(false)
+	cp.SetBounded(false)
+
+	// Set vertical box layout for the whole panel
+	l := gui.NewVBoxLayout()
+	l.SetSpacing(4)
+	cp.SetLayout(l)
+
+	cp.title = gui.NewLabel("")
+	cp.Add(cp.title)
+	cp.summary = gui.NewLabel("")
+	cp.Add(cp.summary)
+
+	cp.list = gui.NewVList(0, 0)
+	cp.list.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 5, AlignH: gui.AlignWidth})
+	cp.Add(cp.list)
+
+	// Creates Close button, closing removes the compared archive from the scene
+	cp.bclose = gui.NewButton("Close comparison")
+	cp.bclose.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 0, AlignH: gui.AlignCenter})
+	cp.bclose.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		cp.Dispatch("OnClose", nil)
+	})
+	cp.Add(cp.bclose)
+
+	return cp
+}
+
+// Show shows or hide the comparison panel
+func (cp *ComparePanel) Show(show bool) {
+
+	if !show {
+		cp.SetVisible(false)
+		return
+	}
+	cp.SetVisible(true)
+	width, height := app.App(300, 300, "Compare").GetSize()
+	cp.SetPosition(float32(width)-cp.Width()-10, float32(height)-cp.Height()-10)
+}
+
+// SetReport lists the differences of report
+func (cp *ComparePanel) SetReport(pathA string, pathB string, report *compare.Report) {
+	cp.title.SetText(fmt.Sprintf("%s vs %s", filepath.Base(pathA), filepath.Base(pathB)))
+	cp.summary.SetText(fmt.Sprintf("%d added, %d removed, %d changed, %d same",
+		report.Count(compare.StatusAdded), report.Count(compare.StatusRemoved),
+		report.Count(compare.StatusChanged), report.Count(compare.StatusSame)))
+
+	cp.list.Clear()
+	for _, e := range report.Changes() {
+		item := gui.NewImageLabel(e.String())
+		switch e.Status {
+		case compare.StatusAdded:
+			item.SetIcon(icon.AddCircleOutline)
+		case compare.StatusRemoved:
+			item.SetIcon(icon.RemoveCircleOutline)
+		default:
+			item.SetIcon(icon.Edit)
+		}
+		cp.list.Add(item)
+	}
+}
+
+// buildComparePanel creates the comparison panel
+func (gv *g3nView) buildComparePanel() {
+	gv.cp = NewComparePanel(420, 300)
+	gv.cp.Subscribe("OnClose", func(evname string, ev interface{}) {
+		gv.closeCompare()
+		gv.cp.Show(false)
+	})
+	gv.scene.Add(gv.cp)
+}
+
+// openCompare loads the archive at path next to the opened archive and summarizes the differences.
+// Compared models are built by their own loader once they're in view
+func (gv *g3nView) openCompare(path string) error {
+	if gv.archivePath == "" {
+		return fmt.Errorf("open an archive before comparing")
+	}
+	// the opened archive is compared as shown, including hot reloaded changes
+	a := gv.archiveQuail
+	b := &quail.Quail{}
+	err := b.PfsRead(path)
+	if err != nil {
+		return fmt.Errorf("pfs read %s: %w", path, err)
+	}
+
+	gv.closeCompare()
+
+	// compared models sit beside the model of the same name, new models go after the last one
+	l := newArchiveLoader(path, b, false)
+	offset := gv.sceneWidth * 1.25
+	nextZ := float32(len(a.Models)) * 2
+	for i, model := range b.Models {
+		am := &archiveModel{index: i, model: model, position: math32.Vector3{X: offset, Z: nextZ}}
+		var match *archiveModel
+		if gv.loader != nil {
+			match = gv.loader.model(model.Header.Name)
+		}
+		if match != nil {
+			am.position = match.position
+			am.position.X += offset
+		} else if !gv.isZone {
+			nextZ += 2
+		}
+		am.bounds = mesh.Bounds(model)
+		am.bounds.Translate(&am.position)
+		l.models = append(l.models, am)
+	}
+	gv.compareLoader = l
+	go l.prepare()
+
+	report := compare.Diff(a, b)
+	for _, e := range report.Changes() {
+		fmt.Println(e.String())
+	}
+	gv.cp.SetReport(gv.archivePath, path, report)
+	gv.cp.Show(true)
+	return nil
+}
+
+// updateCompareLoad builds the compared models in view and evicts those over the memory
+// budget, called each frame
+func (gv *g3nView) updateCompareLoad() {
+	l := gv.compareLoader
+	if l == nil {
+		return
+	}
+	gv.receivePrepared(l)
+	now := time.Now()
+	gv.requestVisible(l, now)
+
+	// at least one model is built each frame so a slow upload can't stall loading
+	start := time.Now()
+	for _, am := range l.models {
+		if am.state != modelPrepared {
+			continue
+		}
+		gv.addCompareModel(am)
+		am.lastViewed = now
+		l.shown++
+		if time.Since(start) > loadFrameBudget {
+			break
+		}
+	}
+	gv.evictModels(l, now, gv.evictCompareModel)
+	if l.isIdle() {
+		l.requested = 0
+		l.shown = 0
+	}
+}
+
+// addCompareModel builds the prepared compared model am at its position beside the opened archive
+func (gv *g3nView) addCompareModel(am *archiveModel) {
+	m := am.prepared.Build()
+	am.size = am.prepared.Size()
+	am.prepared = nil
+	am.state = modelShown
+	m.SetPositionVec(&am.position)
+	m.SetName(am.model.Header.Name + " (compare)")
+	gv.scene.Add(m)
+	gv.compareNodes = append(gv.compareNodes, m)
+	am.node = m
+}
+
+// evictCompareModel removes the compared model am from the scene, it's built again once in view
+func (gv *g3nView) evictCompareModel(am *archiveModel) {
+	gv.scene.Remove(am.node)
+	am.node.Dispose()
+	for i, node := range gv.compareNodes {
+		if node == am.node {
+			gv.compareNodes = append(gv.compareNodes[:i], gv.compareNodes[i+1:]...)
+			break
+		}
+	}
+	am.node = nil
+	am.size = 0
+	am.state = modelUnloaded
+}
+
+// closeCompare removes the compared archive from the scene
+func (gv *g3nView) closeCompare() {
+	if gv.compareLoader != nil {
+		gv.compareLoader.close()
+		gv.compareLoader = nil
+	}
+	for _, node := range gv.compareNodes {
+		gv.scene.Remove(node)
+		node.Dispose()
+	}
+	gv.compareNodes = nil
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/xackery/engine/app"
 	"github.com/xackery/engine/gui"
 	"github.com/xackery/engine/math32"
+	"github.com/xackery/engine/window"
 )
 
 type ErrorDialog struct {
@@ -56,3 +58,86 @@ func (e *ErrorDialog) Show(msg string) {
 	py := (float32(height) - e.Height()) / 2
 	e.SetPosition(px, py)
 }
+
+type NameDialog struct {
+	gui.Panel
+	title *gui.Label
+	name  *gui.Edit
+	bok   *gui.Button
+	bcan  *gui.Button
+}
+
+func NewNameDialog(width, height float32) *NameDialog {
+
+	nd := new(NameDialog)
+	nd.Initialize(nd, width, height)
+	nd.SetBorders(2, 2, 2, 2)
+	nd.SetPaddings(4, 4, 4, 4)
+	nd.SetColor(math32.NewColor("White"))
+	nd.SetVisible(false)
+	nd.SetBounded(false)
+
+	// Set vertical box layout for the whole panel
+	l := gui.NewVBoxLayout()
+	l.SetSpacing(4)
+	nd.SetLayout(l)
+
+	// Creates title label
+	nd.title = gui.NewLabel("Name")
+	nd.Add(nd.title)
+
+	// Creates name edit
+	nd.name = gui.NewEdit(int(width)-16, "")
+	nd.name.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 0, AlignH: gui.AlignWidth})
+	nd.name.Subscribe(gui.OnKeyDown, func(evname string, ev interface{}) {
+		kev := ev.(*window.KeyEvent)
+		if kev.Key == window.KeyEnter || kev.Key == window.KeyKPEnter {
+			nd.Dispatch("OnOK", nil)
+		}
+	})
+	nd.Add(nd.name)
+
+	// Button container panel
+	bc := gui.NewPanel(0, 0)
+	bcl := gui.NewHBoxLayout()
+	bcl.SetAlignH(gui.AlignWidth)
+	bc.SetLayout(bcl)
+	bc.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 1, AlignH: gui.AlignWidth})
+	nd.Add(bc)
+
+	// Creates OK button
+	nd.bok = gui.NewButton("OK")
+	nd.bok.SetLayoutParams(&gui.HBoxLayoutParams{Expand: 0, AlignV: gui.AlignCenter})
+	nd.bok.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		nd.Dispatch("OnOK", nil)
+	})
+	bc.Add(nd.bok)
+
+	// Creates Cancel button
+	nd.bcan = gui.NewButton("Cancel")
+	nd.bcan.SetLayoutParams(&gui.HBoxLayoutParams{Expand: 0, AlignV: gui.AlignCenter})
+	nd.bcan.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		nd.SetVisible(false)
+	})
+	bc.Add(nd.bcan)
+
+	return nd
+}
+
+// Show shows the name dialog with a title and initial name
+func (nd *NameDialog) Show(title string, name string) {
+
+	nd.title.SetText(title)
+	nd.name.SetText(name)
+	nd.SetVisible(true)
+	gui.Manager().SetKeyFocus(nd.name)
+	width, height := app.App(300, 300, "Name").GetSize()
+	px := (float32(width) - nd.Width()) / 2
+	py := (float32(height) - nd.Height()) / 2
+	nd.SetPosition(px, py)
+}
+
+// Name returns the entered name
+func (nd *NameDialog) Name() string {
+	return strings.TrimSpace(nd.name.Text())
+}
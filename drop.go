@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/xackery/engine/window"
+)
+
+// onDrop is dispatched by the window with the dropped file paths, the engine has no drop event
+const onDrop = "w.OnDrop"
+
+// setupDrop loads files dropped onto the window as if opened from the file menu
+func (gv *g3nView) setupDrop() {
+	win, ok := gv.IWindow.(*window.GlfwWindow)
+	if !ok {
+		fmt.Println("Window does not support drag and drop")
+		return
+	}
+	win.SetDropCallback(func(w *glfw.Window, names []string) {
+		win.Dispatch(onDrop, names)
+	})
+	win.Subscribe(onDrop, func(evname string, ev interface{}) {
+		gv.openDropped(ev.([]string))
+	})
+}
+
+// openDropped opens the first dropped archive and every other model file, reporting
+// failures and ignored archives in one error dialog. Archives are opened first since
+// they replace the scene
+func (gv *g3nView) openDropped(names []string) {
+	archives := []string{}
+	others := []string{}
+	for _, name := range names {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext == ".eqg" || ext == ".s3d" {
+			archives = append(archives, name)
+			continue
+		}
+		others = append(others, name)
+	}
+
+	failures := []string{}
+	if len(archives) > 0 {
+		others = append([]string{archives[0]}, others...)
+		for _, name := range archives[1:] {
+			fmt.Println("Ignored dropped archive", name)
+			failures = append(failures, fmt.Sprintf("%s: ignored, only one archive can be open", filepath.Base(name)))
+		}
+	}
+	for _, name := range others {
+		err := gv.openModel(name)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", filepath.Base(name), err))
+			continue
+		}
+		fmt.Println("Opened dropped file", name)
+	}
+	if len(failures) > 0 {
+		gv.ed.Show(strings.Join(failures, "\n"))
+	}
+}
@@ -0,0 +1,88 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xackery/engine/math32"
+)
+
+// BVH writes c as a BVH motion capture file. Every joint has position and rotation
+// channels, rotations are XYZ euler degrees and bone scale is dropped as BVH has none.
+// BVH has no metadata, so the source model is the root joint's name prefix and
+// FileName records both the model and animation
+func BVH(w io.Writer, c *Clip) error {
+	bw := bufio.NewWriter(w)
+	children := make([][]int, len(c.Bones))
+	for i, parent := range c.Parents {
+		if parent >= 0 {
+			children[parent] = append(children[parent], i)
+		}
+	}
+
+	// bones are written depth first, which is also the order of the motion columns
+	order := []int{}
+	var writeJoint func(bone int, depth int)
+	writeJoint = func(bone int, depth int) {
+		indent := strings.Repeat("\t", depth)
+		kind := "JOINT"
+		name := c.Bones[bone].Name
+		if depth == 0 {
+			kind = "ROOT"
+			name = c.Source + ":" + name
+		}
+		pivot := c.Bones[bone].Pivot
+		fmt.Fprintf(bw, "%s%s %s\n%s{\n", indent, kind, jointName(name), indent)
+		fmt.Fprintf(bw, "%s\tOFFSET %f %f %f\n", indent, pivot.X, pivot.Y, pivot.Z)
+		fmt.Fprintf(bw, "%s\tCHANNELS 6 Xposition Yposition Zposition Xrotation Yrotation Zrotation\n", indent)
+		order = append(order, bone)
+		for _, child := range children[bone] {
+			writeJoint(child, depth+1)
+		}
+		if len(children[bone]) == 0 {
+			fmt.Fprintf(bw, "%s\tEnd Site\n%s\t{\n%s\t\tOFFSET 0 0 0\n%s\t}\n", indent, indent, indent, indent)
+		}
+		fmt.Fprintf(bw, "%s}\n", indent)
+	}
+
+	fmt.Fprintln(bw, "HIERARCHY")
+	// BVH has a single root, bones unreachable from the first bone are left out
+	writeJoint(0, 0)
+
+	times := c.Times()
+	frameTime := float32(defaultFrameTime)
+	if len(times) > 1 {
+		frameTime = times[len(times)-1] / float32(len(times)-1)
+	}
+	fmt.Fprintln(bw, "MOTION")
+	fmt.Fprintf(bw, "Frames: %d\n", len(times))
+	fmt.Fprintf(bw, "Frame Time: %f\n", frameTime)
+	for frame := range times {
+		values := []string{}
+		for _, bone := range order {
+			t, r, _ := c.Sample(bone, frame)
+			var euler math32.Vector3
+			euler.SetFromQuaternion(&math32.Quaternion{X: r.X, Y: r.Y, Z: r.Z, W: r.W})
+			values = append(values,
+				fmt.Sprintf("%f %f %f", t.X, t.Y, t.Z),
+				fmt.Sprintf("%f %f %f", math32.RadToDeg(euler.X), math32.RadToDeg(euler.Y), math32.RadToDeg(euler.Z)))
+		}
+		fmt.Fprintln(bw, strings.Join(values, " "))
+	}
+
+	err := bw.Flush()
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+// jointName replaces whitespace, which would split a joint name in BVH
+func jointName(name string) string {
+	if name == "" {
+		return "bone"
+	}
+	return strings.Join(strings.Fields(name), "_")
+}
@@ -0,0 +1,133 @@
+// Package export writes archive animations to formats animators can retime in other tools
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xackery/quail-view/anim"
+	"github.com/xackery/quail-view/skeleton"
+	"github.com/xackery/quail/common"
+)
+
+// defaultFrameTime is the spacing in seconds of frames without usable timestamps
+const defaultFrameTime = 0.1
+
+// Clip is an animation matched to the bones of the model it plays on
+type Clip struct {
+	Name    string                         // Animation name
+	Source  string                         // Model the bones came from
+	Bones   []common.Bone                  // Bones of the source model
+	Parents []int                          // Parent index of each bone, -1 for roots
+	Tracks  [][]*common.BoneAnimationFrame // Frames of each bone, nil for bones the animation doesn't move
+}
+
+// NewClip matches the bone animations of animation to the bones of model by name
+func NewClip(model *common.Model, animation *common.Animation) (*Clip, error) {
+	if len(model.Bones) == 0 {
+		return nil, fmt.Errorf("model %s has no bones", model.Header.Name)
+	}
+	c := &Clip{
+		Name:    animation.Header.Name,
+		Source:  model.Header.Name,
+		Bones:   model.Bones,
+		Parents: skeleton.Parents(model.Bones),
+		Tracks:  make([][]*common.BoneAnimationFrame, len(model.Bones)),
+	}
+
+	bones := make(map[string]int)
+	for i, bone := range model.Bones {
+		bones[strings.ToLower(bone.Name)] = i
+		bones[anim.BoneKey(bone.Name, model.Header.Name)] = i
+	}
+	matched := 0
+	for _, track := range animation.Bones {
+		i, ok := bones[strings.ToLower(track.Name)]
+		if !ok {
+			i, ok = bones[anim.BoneKey(track.Name, "")]
+		}
+		if !ok || len(track.Frames) == 0 {
+			continue
+		}
+		c.Tracks[i] = track.Frames
+		matched++
+	}
+	if matched == 0 {
+		return nil, fmt.Errorf("animation %s moves no bones of %s", c.Name, c.Source)
+	}
+	return c, nil
+}
+
+// FrameCount returns the frames of the longest track
+func (c *Clip) FrameCount() int {
+	count := 0
+	for _, track := range c.Tracks {
+		if len(track) > count {
+			count = len(track)
+		}
+	}
+	return count
+}
+
+// Times returns the time in seconds of each frame, from the timestamps of the longest
+// track when they increase, otherwise spaced by defaultFrameTime
+func (c *Clip) Times() []float32 {
+	var longest []*common.BoneAnimationFrame
+	for _, track := range c.Tracks {
+		if len(track) > len(longest) {
+			longest = track
+		}
+	}
+	times := make([]float32, len(longest))
+	isTimed := len(longest) > 1
+	for i := 1; i < len(longest); i++ {
+		if longest[i].Milliseconds <= longest[i-1].Milliseconds {
+			isTimed = false
+			break
+		}
+	}
+	for i, frame := range longest {
+		if isTimed {
+			times[i] = float32(frame.Milliseconds-longest[0].Milliseconds) / 1000
+			continue
+		}
+		times[i] = float32(i) * defaultFrameTime
+	}
+	return times
+}
+
+// Sample returns the local translation, rotation and scale of bone at frame. Short
+// tracks hold their last frame and unmoved bones keep their bind pose
+func (c *Clip) Sample(bone int, frame int) (common.Vector3, common.Quad4, common.Vector3) {
+	track := c.Tracks[bone]
+	if len(track) == 0 {
+		b := c.Bones[bone]
+		return b.Pivot, b.Rotation, unitScale(b.Scale)
+	}
+	if frame >= len(track) {
+		frame = len(track) - 1
+	}
+	f := track[frame]
+	return f.Translation, f.Rotation, unitScale(f.Scale)
+}
+
+// FileName returns a file name naming the source model and animation
+func (c *Clip) FileName(ext string) string {
+	return safeName(c.Source) + "_" + safeName(c.Name) + ext
+}
+
+// unitScale replaces an unset scale with 1
+func unitScale(scale common.Vector3) common.Vector3 {
+	if scale.X == 0 && scale.Y == 0 && scale.Z == 0 {
+		return common.Vector3{X: 1, Y: 1, Z: 1}
+	}
+	return scale
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-z0-9_\-]+`)
+
+// safeName lower cases name and replaces characters unsuitable for file names
+func safeName(name string) string {
+	return unsafeChars.ReplaceAllString(strings.ToLower(name), "_")
+}
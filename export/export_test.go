@@ -0,0 +1,89 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/xackery/quail/common"
+)
+
+func testClip(t *testing.T) *Clip {
+	identity := common.Quad4{W: 1}
+	one := common.Vector3{X: 1, Y: 1, Z: 1}
+	model := &common.Model{
+		Header: &common.Header{Name: "elf"},
+		Bones: []common.Bone{
+			{Name: "elfroot", Next: -1, ChildrenCount: 1, ChildIndex: 1, Rotation: identity, Scale: one},
+			{Name: "elfhead", Next: -1, Pivot: common.Vector3{Y: 2}, Rotation: identity, Scale: one},
+		},
+	}
+	animation := &common.Animation{
+		Header: &common.Header{Name: "c01"},
+		Bones: []*common.BoneAnimation{
+			{Name: "head", Frames: []*common.BoneAnimationFrame{
+				{Milliseconds: 0, Translation: common.Vector3{Y: 2}, Rotation: identity, Scale: one},
+				{Milliseconds: 250, Translation: common.Vector3{Y: 3}, Rotation: identity, Scale: one},
+			}},
+		},
+	}
+	c, err := NewClip(model, animation)
+	if err != nil {
+		t.Fatalf("new clip: %s", err)
+	}
+	return c
+}
+
+func TestClip(t *testing.T) {
+	c := testClip(t)
+	if c.Tracks[1] == nil || c.Tracks[0] != nil {
+		t.Fatalf("head track not matched to elfhead")
+	}
+	times := c.Times()
+	if len(times) != 2 || times[1] != 0.25 {
+		t.Fatalf("times %v, expected [0 0.25]", times)
+	}
+	if c.FileName(".bvh") != "elf_c01.bvh" {
+		t.Fatalf("file name %s", c.FileName(".bvh"))
+	}
+}
+
+func TestGLTF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := GLTF(buf, testClip(t))
+	if err != nil {
+		t.Fatalf("gltf: %s", err)
+	}
+	data := buf.Bytes()
+	if binary.LittleEndian.Uint32(data[0:4]) != glbMagic || int(binary.LittleEndian.Uint32(data[8:12])) != len(data) {
+		t.Fatalf("bad glb header")
+	}
+	jsonLength := binary.LittleEndian.Uint32(data[12:16])
+	doc := &gltfDoc{}
+	err = json.Unmarshal(data[20:20+jsonLength], doc)
+	if err != nil {
+		t.Fatalf("unmarshal: %s", err)
+	}
+	if doc.Asset.Extras.Animation != "c01" || doc.Asset.Extras.Source != "elf" {
+		t.Fatalf("origin %+v", doc.Asset.Extras)
+	}
+	if len(doc.Animations) != 1 || len(doc.Animations[0].Channels) != 3 {
+		t.Fatalf("expected one animation with 3 channels")
+	}
+}
+
+func TestBVH(t *testing.T) {
+	buf := &bytes.Buffer{}
+	err := BVH(buf, testClip(t))
+	if err != nil {
+		t.Fatalf("bvh: %s", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"ROOT elf:elfroot", "JOINT elfhead", "Frames: 2", "Frame Time: 0.250000"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("bvh missing %q:\n%s", want, out)
+		}
+	}
+}
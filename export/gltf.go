@@ -0,0 +1,251 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/skeleton"
+)
+
+// glTF constants used by the exported skeleton and animation
+const (
+	glbMagic        = 0x46546C67 // "glTF"
+	glbChunkJSON    = 0x4E4F534A // "JSON"
+	glbChunkBIN     = 0x004E4942 // "BIN\x00"
+	componentFloat  = 5126
+	interpolation   = "LINEAR"
+	assetGenerator  = "quail-view"
+	assetGLTFFormat = "2.0"
+)
+
+type gltfDoc struct {
+	Asset       gltfAsset       `json:"asset"`
+	Scene       int             `json:"scene"`
+	Scenes      []gltfScene     `json:"scenes"`
+	Nodes       []gltfNode      `json:"nodes"`
+	Skins       []gltfSkin      `json:"skins"`
+	Animations  []gltfAnimation `json:"animations"`
+	Accessors   []gltfAccessor  `json:"accessors"`
+	BufferViews []gltfView      `json:"bufferViews"`
+	Buffers     []gltfBuffer    `json:"buffers"`
+}
+
+type gltfAsset struct {
+	Version   string     `json:"version"`
+	Generator string     `json:"generator"`
+	Extras    gltfOrigin `json:"extras"`
+}
+
+// gltfOrigin records which animation and model an export came from
+type gltfOrigin struct {
+	Animation string `json:"animation"`
+	Source    string `json:"source"`
+}
+
+type gltfScene struct {
+	Name  string `json:"name"`
+	Nodes []int  `json:"nodes"`
+}
+
+type gltfNode struct {
+	Name        string     `json:"name"`
+	Children    []int      `json:"children,omitempty"`
+	Translation [3]float32 `json:"translation"`
+	Rotation    [4]float32 `json:"rotation"`
+	Scale       [3]float32 `json:"scale"`
+}
+
+type gltfSkin struct {
+	Name                string `json:"name"`
+	Joints              []int  `json:"joints"`
+	InverseBindMatrices int    `json:"inverseBindMatrices"`
+}
+
+type gltfAnimation struct {
+	Name     string        `json:"name"`
+	Channels []gltfChannel `json:"channels"`
+	Samplers []gltfSampler `json:"samplers"`
+	Extras   gltfOrigin    `json:"extras"`
+}
+
+type gltfChannel struct {
+	Sampler int        `json:"sampler"`
+	Target  gltfTarget `json:"target"`
+}
+
+type gltfTarget struct {
+	Node int    `json:"node"`
+	Path string `json:"path"`
+}
+
+type gltfSampler struct {
+	Input         int    `json:"input"`
+	Output        int    `json:"output"`
+	Interpolation string `json:"interpolation"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type gltfView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+}
+
+type gltfBuffer struct {
+	ByteLength int `json:"byteLength"`
+}
+
+// gltfWriter collects the binary buffer and the accessors addressing it
+type gltfWriter struct {
+	doc *gltfDoc
+	bin bytes.Buffer
+}
+
+// accessor appends values to the binary buffer and returns the accessor index
+func (gw *gltfWriter) accessor(kind string, components int, values []float32) int {
+	view := gltfView{ByteOffset: gw.bin.Len(), ByteLength: len(values) * 4}
+	for _, v := range values {
+		binary.Write(&gw.bin, binary.LittleEndian, math.Float32bits(v))
+	}
+	gw.doc.BufferViews = append(gw.doc.BufferViews, view)
+	gw.doc.Accessors = append(gw.doc.Accessors, gltfAccessor{
+		BufferView:    len(gw.doc.BufferViews) - 1,
+		ComponentType: componentFloat,
+		Count:         len(values) / components,
+		Type:          kind,
+	})
+	return len(gw.doc.Accessors) - 1
+}
+
+// GLTF writes c as a binary glTF holding only the skeleton of the source model and
+// one animation driving it
+func GLTF(w io.Writer, c *Clip) error {
+	origin := gltfOrigin{Animation: c.Name, Source: c.Source}
+	doc := &gltfDoc{
+		Asset: gltfAsset{Version: assetGLTFFormat, Generator: assetGenerator, Extras: origin},
+	}
+	gw := &gltfWriter{doc: doc}
+
+	scene := gltfScene{Name: c.Source}
+	skin := gltfSkin{Name: c.Source}
+	for i, bone := range c.Bones {
+		t, r, s := bone.Pivot, bone.Rotation, unitScale(bone.Scale)
+		doc.Nodes = append(doc.Nodes, gltfNode{
+			Name:        bone.Name,
+			Translation: [3]float32{t.X, t.Y, t.Z},
+			Rotation:    [4]float32{r.X, r.Y, r.Z, r.W},
+			Scale:       [3]float32{s.X, s.Y, s.Z},
+		})
+		skin.Joints = append(skin.Joints, i)
+	}
+	for i, parent := range c.Parents {
+		if parent < 0 {
+			scene.Nodes = append(scene.Nodes, i)
+			continue
+		}
+		doc.Nodes[parent].Children = append(doc.Nodes[parent].Children, i)
+	}
+
+	ibms := []float32{}
+	for _, world := range skeleton.BindPose(c.Bones) {
+		var ibm math32.Matrix4
+		err := ibm.GetInverse(&world)
+		if err != nil {
+			ibm.Identity()
+		}
+		ibms = append(ibms, ibm[:]...)
+	}
+	skin.InverseBindMatrices = gw.accessor("MAT4", 16, ibms)
+	doc.Scenes = []gltfScene{scene}
+	doc.Skins = []gltfSkin{skin}
+
+	times := c.Times()
+	input := gw.accessor("SCALAR", 1, times)
+	if len(times) > 0 {
+		doc.Accessors[input].Min = []float32{times[0]}
+		doc.Accessors[input].Max = []float32{times[len(times)-1]}
+	}
+
+	animation := gltfAnimation{Name: c.Name, Extras: origin}
+	for bone, track := range c.Tracks {
+		if len(track) == 0 {
+			continue
+		}
+		var translations, rotations, scales []float32
+		for frame := range times {
+			t, r, s := c.Sample(bone, frame)
+			translations = append(translations, t.X, t.Y, t.Z)
+			rotations = append(rotations, r.X, r.Y, r.Z, r.W)
+			scales = append(scales, s.X, s.Y, s.Z)
+		}
+		for _, output := range []struct {
+			path       string
+			kind       string
+			components int
+			values     []float32
+		}{
+			{"translation", "VEC3", 3, translations},
+			{"rotation", "VEC4", 4, rotations},
+			{"scale", "VEC3", 3, scales},
+		} {
+			animation.Samplers = append(animation.Samplers, gltfSampler{
+				Input:         input,
+				Output:        gw.accessor(output.kind, output.components, output.values),
+				Interpolation: interpolation,
+			})
+			animation.Channels = append(animation.Channels, gltfChannel{
+				Sampler: len(animation.Samplers) - 1,
+				Target:  gltfTarget{Node: bone, Path: output.path},
+			})
+		}
+	}
+	doc.Animations = []gltfAnimation{animation}
+	doc.Buffers = []gltfBuffer{{ByteLength: gw.bin.Len()}}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	return writeGLB(w, data, gw.bin.Bytes())
+}
+
+// writeGLB writes the glb header followed by the json and binary chunks, each padded
+// to four bytes
+func writeGLB(w io.Writer, data []byte, bin []byte) error {
+	for len(data)%4 != 0 {
+		data = append(data, ' ')
+	}
+	for len(bin)%4 != 0 {
+		bin = append(bin, 0)
+	}
+	length := 12 + 8 + len(data) + 8 + len(bin)
+
+	buf := &bytes.Buffer{}
+	for _, v := range []uint32{glbMagic, 2, uint32(length), uint32(len(data)), glbChunkJSON} {
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+	buf.Write(data)
+	for _, v := range []uint32{uint32(len(bin)), glbChunkBIN} {
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+	buf.Write(bin)
+
+	_, err := w.Write(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
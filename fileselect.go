@@ -1,25 +1,67 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/xackery/engine/app"
 	"github.com/xackery/engine/gui"
 	"github.com/xackery/engine/gui/assets/icon"
 	"github.com/xackery/engine/math32"
+	"github.com/xackery/engine/window"
 )
 
+// doubleClickTime is the longest gap between two clicks of a double click
+const doubleClickTime = 400 * time.Millisecond
+
+type fileFilter struct {
+	name string
+	exts []string
+}
+
+// fileFilters are the choices of the file type drop down, the first is the default
+var fileFilters = []*fileFilter{
+	{name: "Supported files", exts: []string{".s3d", ".eqg", ".obj", ".dae", ".gltf", ".glb"}},
+	{name: "EQ archives (.s3d, .eqg)", exts: []string{".s3d", ".eqg"}},
+	{name: "Wavefront (.obj)", exts: []string{".obj"}},
+	{name: "Collada (.dae)", exts: []string{".dae"}},
+	{name: "glTF (.gltf, .glb)", exts: []string{".gltf", ".glb"}},
+	{name: "All files"},
+}
+
+// match returns true if name passes the filter
+func (ff *fileFilter) match(name string) bool {
+	if len(ff.exts) == 0 {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range ff.exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
 type FileSelect struct {
 	gui.Panel
-	path *gui.Label
-	list *gui.List
-	bok  *gui.Button
-	bcan *gui.Button
+	dir       string
+	path      *gui.Edit
+	filter    *gui.DropDown
+	list      *gui.List
+	files     []os.FileInfo
+	bok       *gui.Button
+	bcan      *gui.Button
+	lastClick *gui.ListItem
+	lastTime  time.Time
+	typed     string // File typed in the path box that the filter hides
 }
 
-func NewFileSelect(width, height float32) (*FileSelect, error) {
+func NewFileSelect(width, height float32, dir string) (*FileSelect, error) {
 
 	fs := new(FileSelect)
 	fs.Panel.Initialize(fs, width, height)
@@ -34,16 +76,33 @@ func NewFileSelect(width, height float32) (*FileSelect, error) {
 	l.SetSpacing(4)
 	fs.SetLayout(l)
 
-	// Creates path label
-	fs.path = gui.NewLabel("path")
+	// Creates path box, Enter opens the typed directory or file
+	fs.path = gui.NewEdit(int(width)-16, "path")
+	fs.path.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 0, AlignH: gui.AlignWidth})
+	fs.path.Subscribe(gui.OnKeyDown, func(evname string, ev interface{}) {
+		kev := ev.(*window.KeyEvent)
+		if kev.Key == window.KeyEnter || kev.Key == window.KeyKPEnter {
+			fs.onPathEnter()
+		}
+	})
 	fs.Add(fs.path)
 
+	// Creates file type filter
+	fs.filter = gui.NewDropDown(width-16, gui.NewImageLabel(""))
+	for _, ff := range fileFilters {
+		fs.filter.Add(gui.NewImageLabel(ff.name))
+	}
+	fs.filter.SelectPos(0)
+	fs.filter.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		fs.refresh()
+	})
+	fs.Add(fs.filter)
+
 	// Creates list
 	fs.list = gui.NewVList(0, 0)
+	fs.list.SetSingle(true)
 	fs.list.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 5, AlignH: gui.AlignWidth})
-	fs.list.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
-		fs.onSelect()
-	})
+	fs.list.Subscribe(gui.OnKeyDown, fs.onKey)
 	fs.Add(fs.list)
 
 	// Button container panel
@@ -58,7 +117,7 @@ func NewFileSelect(width, height float32) (*FileSelect, error) {
 	fs.bok = gui.NewButton("OK")
 	fs.bok.SetLayoutParams(&gui.HBoxLayoutParams{Expand: 0, AlignV: gui.AlignCenter})
 	fs.bok.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
-		fs.Dispatch("OnOK", nil)
+		fs.activate()
 	})
 	bc.Add(fs.bok)
 
@@ -70,12 +129,17 @@ func NewFileSelect(width, height float32) (*FileSelect, error) {
 	})
 	bc.Add(fs.bcan)
 
-	// Sets initial directory
+	// Sets initial directory, falling back to the working directory
+	if dir != "" && fs.SetPath(dir) == nil {
+		return fs, nil
+	}
 	path, err := os.Getwd()
 	if err != nil {
 		return nil, err
-	} else {
-		fs.SetPath(path)
+	}
+	err = fs.SetPath(path)
+	if err != nil {
+		return nil, err
 	}
 	return fs, nil
 }
@@ -89,11 +153,13 @@ func (fs *FileSelect) Show(show bool) {
 		px := (float32(width) - fs.Width()) / 2
 		py := (float32(height) - fs.Height()) / 2
 		fs.SetPosition(px, py)
+		gui.Manager().SetKeyFocus(fs.list)
 	} else {
 		fs.SetVisible(false)
 	}
 }
 
+// SetPath lists the contents of the directory path
 func (fs *FileSelect) SetPath(path string) error {
 
 	// Open path file or dir
@@ -106,74 +172,179 @@ func (fs *FileSelect) SetPath(path string) error {
 	// Checks if it is a directory
 	files, err := f.Readdir(0)
 	if err != nil {
-		return err
+		return fmt.Errorf("read dir %s: %w", path, err)
 	}
+	fs.dir = path
 	fs.path.SetText(path)
 
-	// Sort files by name
+	// Sort files with directories first, then by name ignoring case
 	sort.Sort(listFileInfo(files))
+	fs.files = files
+	fs.refresh()
+	return nil
+}
+
+// refresh lists the files of the current directory passing the filter
+func (fs *FileSelect) refresh() {
+	ff := fileFilters[0]
+	pos := fs.filter.SelectedPos()
+	if pos >= 0 && pos < len(fileFilters) {
+		ff = fileFilters[pos]
+	}
 
-	// Reads directory contents and loads into the list
 	fs.list.Clear()
+	fs.lastClick = nil
+	fs.typed = ""
 	// Adds previous directory
 	prev := gui.NewImageLabel("..")
 	prev.SetIcon(icon.FolderOpen)
-	fs.list.Add(prev)
+	fs.addItem(prev)
 	// Adds directory files
-	for i := 0; i < len(files); i++ {
-		item := gui.NewImageLabel(files[i].Name())
-		if files[i].IsDir() {
+	for i := 0; i < len(fs.files); i++ {
+		fi := fs.files[i]
+		if !fi.IsDir() && !ff.match(fi.Name()) {
+			continue
+		}
+		item := gui.NewImageLabel(fi.Name())
+		if fi.IsDir() {
 			item.SetIcon(icon.FolderOpen)
 		} else {
 			item.SetIcon(icon.InsertPhoto)
 		}
-		fs.list.Add(item)
+		fs.addItem(item)
 	}
-	return nil
 }
 
+// addItem adds an entry to the list, opening it on double click
+func (fs *FileSelect) addItem(item *gui.ImageLabel) {
+	litem := fs.list.Add(item)
+	litem.Subscribe(gui.OnMouseDown, func(evname string, ev interface{}) {
+		fs.typed = ""
+		now := time.Now()
+		isDouble := fs.lastClick == litem && now.Sub(fs.lastTime) < doubleClickTime
+		fs.lastClick = litem
+		fs.lastTime = now
+		if isDouble {
+			fs.lastClick = nil
+			fs.activate()
+		}
+	})
+}
+
+// Selected returns the path of the selected entry, or of a file typed in the path box
 func (fs *FileSelect) Selected() string {
 
+	if fs.typed != "" {
+		return fs.typed
+	}
 	selist := fs.list.Selected()
 	if len(selist) == 0 {
 		return ""
 	}
 	label := selist[0].(*gui.ImageLabel)
 	text := label.Text()
-	return filepath.Join(fs.path.Text(), text)
+	return filepath.Join(fs.dir, text)
 }
 
-func (fs *FileSelect) onSelect() {
+// activate enters the selected directory, or dispatches OnOK for a selected file
+func (fs *FileSelect) activate() {
 
-	// Get selected image label and its txt
-	sel := fs.list.Selected()[0]
-	label := sel.(*gui.ImageLabel)
-	text := label.Text()
+	fs.typed = ""
+	selist := fs.list.Selected()
+	if len(selist) == 0 {
+		fs.Dispatch("OnOK", nil)
+		return
+	}
+	text := selist[0].(*gui.ImageLabel).Text()
 
 	// Checks if previous directory
 	if text == ".." {
-		dir, _ := filepath.Split(fs.path.Text())
-		fs.SetPath(filepath.Dir(dir))
+		fs.up()
 		return
 	}
 
 	// Checks if it is a directory
-	path := filepath.Join(fs.path.Text(), text)
+	path := filepath.Join(fs.dir, text)
+	s, err := os.Stat(path)
+	if err != nil {
+		fs.Dispatch("OnError", err)
+		return
+	}
+	if s.IsDir() {
+		fs.changeDir(path)
+		return
+	}
+	fs.Dispatch("OnOK", nil)
+}
+
+// up lists the parent of the current directory
+func (fs *FileSelect) up() {
+	fs.changeDir(filepath.Dir(fs.dir))
+}
+
+// changeDir lists path, dispatching OnError if it can't be read
+func (fs *FileSelect) changeDir(path string) {
+	err := fs.SetPath(path)
+	if err != nil {
+		fs.Dispatch("OnError", err)
+		return
+	}
+	gui.Manager().SetKeyFocus(fs.list)
+}
+
+// onPathEnter opens the path typed in the path box
+func (fs *FileSelect) onPathEnter() {
+	path := strings.TrimSpace(fs.path.Text())
 	s, err := os.Stat(path)
 	if err != nil {
-		panic(err)
+		fs.Dispatch("OnError", err)
+		return
 	}
 	if s.IsDir() {
-		fs.SetPath(path)
+		fs.changeDir(path)
+		return
+	}
+	err = fs.SetPath(filepath.Dir(path))
+	if err != nil {
+		fs.Dispatch("OnError", err)
+		return
+	}
+	for i := 0; i < fs.list.Len(); i++ {
+		item := fs.list.ItemAt(i).(*gui.ImageLabel)
+		if item.Text() == filepath.Base(path) {
+			fs.list.SelectPos(i, true)
+			fs.Dispatch("OnOK", nil)
+			return
+		}
+	}
+	// an existing file is opened even if the filter hides it
+	fs.typed = filepath.Join(fs.dir, filepath.Base(path))
+	fs.Dispatch("OnOK", nil)
+}
+
+// onKey opens the selection with Enter and goes up a directory with Backspace,
+// arrow keys are handled by the list
+func (fs *FileSelect) onKey(evname string, ev interface{}) {
+	kev := ev.(*window.KeyEvent)
+	switch kev.Key {
+	case window.KeyEnter, window.KeyKPEnter:
+		fs.activate()
+	case window.KeyBackspace:
+		fs.up()
+	case window.KeyEscape:
+		fs.Dispatch("OnCancel", nil)
 	}
 }
 
-// For sorting array of FileInfo by Name
+// For sorting array of FileInfo with directories first, then by name ignoring case
 type listFileInfo []os.FileInfo
 
 func (fi listFileInfo) Len() int      { return len(fi) }
 func (fi listFileInfo) Swap(i, j int) { fi[i], fi[j] = fi[j], fi[i] }
 func (fi listFileInfo) Less(i, j int) bool {
 
-	return fi[i].Name() < fi[j].Name()
+	if fi[i].IsDir() != fi[j].IsDir() {
+		return fi[i].IsDir()
+	}
+	return strings.ToLower(fi[i].Name()) < strings.ToLower(fi[j].Name())
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/xackery/engine/camera"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/engine/window"
+)
+
+const (
+	flySpeedMin = float32(0.125)
+	flySpeedMax = float32(64)
+)
+
+// newFlyControl creates a WASD and mouse-look fly control for the fps camera
+func (gv *g3nView) newFlyControl() {
+	gv.fly = camera.NewFlyControl(gv.fpsCam, &math32.Vector3{X: 0, Y: 0, Z: 0}, &math32.Vector3{X: 0, Y: 1, Z: 0}, camera.FPSStyle())
+	gv.fly.Keys[camera.Up] = window.KeyE
+	gv.fly.Keys[camera.Down] = window.KeyQ
+	// fly control only listens for input while it is the active camera
+	gv.fly.Unsubscribe(true, true)
+	gv.setFlySpeed(gv.settings.FlySpeed)
+}
+
+// activeCam returns the camera currently used to render the scene
+func (gv *g3nView) activeCam() *camera.Camera {
+	if gv.isFpsCamera {
+		return gv.fpsCam
+	}
+	return gv.cam
+}
+
+// setFlyCamera switches camera as the View menu checkbox does, updating it and the saved setting
+func (gv *g3nView) setFlyCamera(isFps bool) {
+	gv.setFpsCamera(isFps)
+	gv.flyItem.SetIcon(getIcon(gv.isFpsCamera))
+	if gv.settings.FlyCamera == gv.isFpsCamera {
+		return
+	}
+	gv.settings.FlyCamera = gv.isFpsCamera
+	gv.saveSettings()
+}
+
+// setFpsCamera switches between the orbit and fly camera, carrying over position and rotation
+func (gv *g3nView) setFpsCamera(isFps bool) {
+	if gv.isFpsCamera == isFps {
+		return
+	}
+	gv.isFpsCamera = isFps
+
+	if isFps {
+		pos := gv.cam.Position()
+		target := gv.orbit.Target()
+		gv.fly.Reposition(&pos)
+		gv.fly.Reorient(&target, &math32.Vector3{X: 0, Y: 1, Z: 0})
+		gv.fpsCam.LookAt(&target, &math32.Vector3{X: 0, Y: 1, Z: 0})
+		gv.orbit.SetEnabled(camera.OrbitNone)
+		gv.fly.Subscribe(true, true)
+	} else {
+		gv.fly.Unsubscribe(true, true)
+		pos := gv.fly.GetPosition()
+		forward, up := gv.fly.GetDirections()
+		// keep the orbit distance so zoom feels the same after switching back
+		camPos := gv.cam.Position()
+		orbitTarget := gv.orbit.Target()
+		dist := camPos.DistanceTo(&orbitTarget)
+		if dist <= 0 {
+			dist = 10
+		}
+		target := pos.Clone().Add(forward.MultiplyScalar(dist))
+		gv.cam.SetPositionVec(&pos)
+		gv.cam.LookAt(target, &up)
+		gv.orbit.SetTarget(*target)
+		gv.orbit.SetEnabled(camera.OrbitAll)
+	}
+
+	gv.cam.SetVisible(!isFps)
+	gv.fpsCam.SetVisible(isFps)
+}
+
+// setFlySpeed sets how many units the fly camera moves per key press
+func (gv *g3nView) setFlySpeed(speed float32) {
+	if speed < flySpeedMin {
+		speed = flySpeedMin
+	}
+	if speed > flySpeedMax {
+		speed = flySpeedMax
+	}
+	gv.settings.FlySpeed = speed
+	gv.fly.Speeds[camera.Forward] = speed
+	gv.fly.Speeds[camera.Backward] = -speed
+	gv.fly.Speeds[camera.Right] = speed
+	gv.fly.Speeds[camera.Left] = -speed
+	gv.fly.Speeds[camera.Up] = speed
+	gv.fly.Speeds[camera.Down] = -speed
+	fmt.Println("Fly speed", speed)
+}
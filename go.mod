@@ -3,6 +3,7 @@ module github.com/xackery/quail-view
 go 1.21
 
 require (
+	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b
 	github.com/malashin/dds v0.0.0-20190511100755-ab62708f5fe5
 	github.com/sergeymakinen/go-bmp v1.0.0-beta.1
 	github.com/xackery/colors v1.0.1
@@ -11,7 +12,6 @@ require (
 )
 
 require (
-	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20221017161538-93cebf72946b // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/kr/text v0.2.0 // indirect
 	github.com/rogpeppe/go-internal v1.9.0 // indirect
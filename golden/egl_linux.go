@@ -0,0 +1,67 @@
+package golden
+
+// #cgo LDFLAGS: -lEGL
+// #include <stdlib.h>
+// #include <EGL/egl.h>
+// #include <EGL/eglext.h>
+//
+// // surfacelessContext makes a 3.3 core context current without a window or display server
+// static const char* surfacelessContext(EGLDisplay* display, EGLContext* context) {
+// 	PFNEGLGETPLATFORMDISPLAYEXTPROC getDisplay = (PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+// 	if (getDisplay == NULL) {
+// 		return "eglGetPlatformDisplayEXT not supported";
+// 	}
+// 	*display = getDisplay(EGL_PLATFORM_SURFACELESS_MESA, EGL_DEFAULT_DISPLAY, NULL);
+// 	if (*display == EGL_NO_DISPLAY) {
+// 		return "no surfaceless display";
+// 	}
+// 	if (!eglInitialize(*display, NULL, NULL)) {
+// 		return "egl initialize failed";
+// 	}
+// 	if (!eglBindAPI(EGL_OPENGL_API)) {
+// 		return "desktop opengl not supported";
+// 	}
+// 	EGLint configAttribs[] = {EGL_SURFACE_TYPE, EGL_PBUFFER_BIT, EGL_RENDERABLE_TYPE, EGL_OPENGL_BIT, EGL_NONE};
+// 	EGLConfig config;
+// 	EGLint count = 0;
+// 	if (!eglChooseConfig(*display, configAttribs, &config, 1, &count) || count == 0) {
+// 		return "no opengl config";
+// 	}
+// 	EGLint contextAttribs[] = {
+// 		EGL_CONTEXT_MAJOR_VERSION, 3,
+// 		EGL_CONTEXT_MINOR_VERSION, 3,
+// 		EGL_CONTEXT_OPENGL_PROFILE_MASK, EGL_CONTEXT_OPENGL_CORE_PROFILE_BIT,
+// 		EGL_NONE,
+// 	};
+// 	*context = eglCreateContext(*display, config, EGL_NO_CONTEXT, contextAttribs);
+// 	if (*context == EGL_NO_CONTEXT) {
+// 		return "create context failed";
+// 	}
+// 	if (!eglMakeCurrent(*display, EGL_NO_SURFACE, EGL_NO_SURFACE, *context)) {
+// 		return "make current failed";
+// 	}
+// 	return NULL;
+// }
+//
+// static void releaseContext(EGLDisplay display, EGLContext context) {
+// 	eglMakeCurrent(display, EGL_NO_SURFACE, EGL_NO_SURFACE, EGL_NO_CONTEXT);
+// 	eglDestroyContext(display, context);
+// 	eglTerminate(display);
+// }
+import "C"
+
+import "fmt"
+
+// surfaceless makes a Mesa EGL context current on the calling thread, for machines
+// without a display server. The returned func releases it
+func surfaceless() (func(), error) {
+	var display C.EGLDisplay
+	var context C.EGLContext
+	msg := C.surfacelessContext(&display, &context)
+	if msg != nil {
+		return nil, fmt.Errorf("egl: %s", C.GoString(msg))
+	}
+	return func() {
+		C.releaseContext(display, context)
+	}, nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package golden
+
+import "fmt"
+
+// surfaceless is only supported through Mesa on linux
+func surfaceless() (func(), error) {
+	return nil, fmt.Errorf("no display")
+}
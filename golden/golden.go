@@ -0,0 +1,206 @@
+// Package golden renders scenes offscreen and compares them against reference images,
+// so rendering changes are caught without a GPU when run under a software GL such as
+// Mesa's llvmpipe, on a virtual X display or headless through EGL
+package golden
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/xackery/engine/camera"
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/gls"
+	"github.com/xackery/engine/renderer"
+)
+
+// Context is an OpenGL context rendering offscreen, through a hidden window or, without
+// a display, a framebuffer of a headless EGL context
+type Context struct {
+	window   *glfw.Window
+	release  func() // Releases the headless context
+	gs       *gls.GLS
+	renderer *renderer.Renderer
+	fbo      uint32
+	width    int
+	height   int
+}
+
+// NewContext creates a width by height offscreen context. It must be called from the
+// goroutine that renders, which is locked to its thread
+func NewContext(width, height int) (*Context, error) {
+	runtime.LockOSThread()
+	if runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return newHeadless(width, height)
+	}
+	err := glfw.Init()
+	if err != nil {
+		return nil, fmt.Errorf("glfw init: %w", err)
+	}
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	// multisampling differs between drivers, golden images are rendered without it
+	glfw.WindowHint(glfw.Samples, 0)
+	if runtime.GOOS == "darwin" {
+		glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	}
+	window, err := glfw.CreateWindow(width, height, "golden", nil, nil)
+	if err != nil {
+		glfw.Terminate()
+		return nil, fmt.Errorf("create window: %w", err)
+	}
+	window.MakeContextCurrent()
+	gs, err := gls.New()
+	if err != nil {
+		window.Destroy()
+		glfw.Terminate()
+		return nil, fmt.Errorf("gls: %w", err)
+	}
+	r, err := newRenderer(gs)
+	if err != nil {
+		window.Destroy()
+		glfw.Terminate()
+		return nil, err
+	}
+	c := &Context{window: window, gs: gs, renderer: r}
+	c.width, c.height = window.GetFramebufferSize()
+	return c, nil
+}
+
+// newHeadless creates a context without a display, rendering to a framebuffer
+func newHeadless(width, height int) (*Context, error) {
+	release, err := surfaceless()
+	if err != nil {
+		return nil, err
+	}
+	gs, err := gls.New()
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("gls: %w", err)
+	}
+	r, err := newRenderer(gs)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	c := &Context{release: release, gs: gs, renderer: r, width: width, height: height}
+	c.fbo = gs.GenFramebuffer()
+	gs.BindFramebuffer(c.fbo)
+	color := gs.GenRenderbuffer()
+	gs.BindRenderbuffer(color)
+	gs.RenderbufferStorage(gls.RGBA8, width, height)
+	gs.FramebufferRenderbuffer(gls.COLOR_ATTACHMENT0, color)
+	depth := gs.GenRenderbuffer()
+	gs.BindRenderbuffer(depth)
+	gs.RenderbufferStorage(gls.DEPTH24_STENCIL8, width, height)
+	gs.FramebufferRenderbuffer(gls.DEPTH_STENCIL_ATTACHMENT, depth)
+	return c, nil
+}
+
+// newRenderer creates a renderer for gs with the default shaders of the engine
+func newRenderer(gs *gls.GLS) (*renderer.Renderer, error) {
+	r := renderer.NewRenderer(gs)
+	err := r.AddDefaultShaders()
+	if err != nil {
+		return nil, fmt.Errorf("add default shaders: %w", err)
+	}
+	return r, nil
+}
+
+// Render draws scene from cam and returns the rendered image
+func (c *Context) Render(scene core.INode, cam camera.ICamera) (*image.RGBA, error) {
+	c.gs.Viewport(0, 0, int32(c.width), int32(c.height))
+	c.gs.ClearColor(0, 0, 0, 1)
+	c.gs.Clear(gls.COLOR_BUFFER_BIT | gls.DEPTH_BUFFER_BIT)
+	err := c.renderer.Render(scene, cam)
+	if err != nil {
+		return nil, fmt.Errorf("render: %w", err)
+	}
+	data := c.gs.ReadPixels(0, 0, c.width, c.height, gls.RGBA, gls.UNSIGNED_BYTE)
+
+	// gl rows start at the bottom
+	img := image.NewRGBA(image.Rect(0, 0, c.width, c.height))
+	stride := c.width * 4
+	for y := 0; y < c.height; y++ {
+		copy(img.Pix[y*stride:(y+1)*stride], data[(c.height-1-y)*stride:(c.height-y)*stride])
+	}
+	return img, nil
+}
+
+// Dispose destroys the window or headless context
+func (c *Context) Dispose() {
+	if c.release != nil {
+		c.release()
+		return
+	}
+	c.window.Destroy()
+	glfw.Terminate()
+}
+
+// Compare returns how many pixels of got differ from want by more than tolerance in
+// any channel
+func Compare(got image.Image, want image.Image, tolerance uint8) (int, error) {
+	if got.Bounds().Size() != want.Bounds().Size() {
+		return 0, fmt.Errorf("size %v, expected %v", got.Bounds().Size(), want.Bounds().Size())
+	}
+	diff := 0
+	gb, wb := got.Bounds(), want.Bounds()
+	for y := 0; y < gb.Dy(); y++ {
+		for x := 0; x < gb.Dx(); x++ {
+			gr, gg, gbl, ga := got.At(gb.Min.X+x, gb.Min.Y+y).RGBA()
+			wr, wg, wbl, wa := want.At(wb.Min.X+x, wb.Min.Y+y).RGBA()
+			for _, pair := range [][2]uint32{{gr, wr}, {gg, wg}, {gbl, wbl}, {ga, wa}} {
+				if channelDiff(pair[0]>>8, pair[1]>>8) > uint32(tolerance) {
+					diff++
+					break
+				}
+			}
+		}
+	}
+	return diff, nil
+}
+
+func channelDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// Load reads the png at path
+func Load(path string) (image.Image, error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+// Save writes img as a png to path, creating its directory
+func Save(path string, img image.Image) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	w, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	defer w.Close()
+	err = png.Encode(w, img)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", path, err)
+	}
+	return nil
+}
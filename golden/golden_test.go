@@ -0,0 +1,121 @@
+package golden
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xackery/engine/camera"
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/internal/fixture"
+	"github.com/xackery/quail-view/lighting"
+	"github.com/xackery/quail-view/mesh"
+)
+
+var (
+	update  = flag.Bool("update", false, "rewrite golden images with the current renders")
+	require = flag.Bool("golden.require", false, "fail instead of skipping when no offscreen context can be created")
+)
+
+const (
+	renderSize = 128
+	// tolerance absorbs rounding differences between software GL versions
+	tolerance = 8
+	// maxDiffPixels is how many pixels may differ by more than tolerance
+	maxDiffPixels = renderSize * renderSize / 200
+)
+
+// TestGolden renders the fixture models under each lighting preset and compares them
+// against testdata/*.png. Without a display it renders headless through Mesa EGL, or
+// run it under xvfb-run with LIBGL_ALWAYS_SOFTWARE=1, and with -update to accept intended changes.
+// Without a GL context it skips, unless -golden.require is set as it is in CI
+func TestGolden(t *testing.T) {
+	// every render shares one context, which is current on this goroutine's thread only
+	c, err := NewContext(renderSize, renderSize)
+	if err != nil && *require {
+		t.Fatalf("no offscreen context: %s", err)
+	}
+	if err != nil {
+		t.Skipf("no offscreen context: %s", err)
+	}
+	defer c.Dispose()
+
+	q := fixture.Archive()
+	for _, rig := range lighting.Presets() {
+		name := "cube_" + rig.Name
+		scene := core.NewNode()
+		cube, err := mesh.Generate(q, q.Models[0])
+		if err != nil {
+			t.Fatalf("generate: %s", err)
+		}
+		scene.Add(cube)
+		lights, err := rig.Generate(2, false)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		for _, l := range lights {
+			scene.Add(l)
+		}
+		cam := camera.NewPerspective(1, 0.1, 100, 45, camera.Vertical)
+		cam.SetPosition(2, 1.5, 3)
+		cam.LookAt(&math32.Vector3{}, &math32.Vector3{Y: 1})
+
+		got, err := c.Render(scene, cam)
+		scene.Dispose()
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		checkGolden(t, name, got)
+	}
+}
+
+// checkGolden compares img against testdata/name.png, or rewrites it with -update
+func checkGolden(t *testing.T, name string, img image.Image) {
+	path := filepath.Join("testdata", name+".png")
+	if *update {
+		err := Save(path, img)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		return
+	}
+	want, err := Load(path)
+	if os.IsNotExist(err) {
+		t.Errorf("%s: no golden image, run with -update to create it", name)
+		return
+	}
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	diff, err := Compare(img, want, tolerance)
+	if err != nil {
+		t.Errorf("%s: %s", name, err)
+		return
+	}
+	if diff > maxDiffPixels {
+		failed := filepath.Join(os.TempDir(), "golden", name+".png")
+		err = Save(failed, img)
+		if err != nil {
+			t.Logf("save failed render: %s", err)
+		}
+		t.Errorf("%s: %d pixels differ, %d allowed, render saved to %s", name, diff, maxDiffPixels, failed)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	b := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	b.Set(0, 0, color.RGBA{R: 4, A: 0})
+	b.Set(1, 1, color.RGBA{R: 200, A: 255})
+	diff, err := Compare(a, b, 8)
+	if err != nil {
+		t.Fatalf("compare: %s", err)
+	}
+	if diff != 1 {
+		t.Fatalf("%d pixels differ, expected 1", diff)
+	}
+}
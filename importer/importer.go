@@ -0,0 +1,255 @@
+// Package importer maps meshes edited in other tools back onto EQ models
+package importer
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/geometry"
+	"github.com/xackery/engine/gls"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/engine/loader/gltf"
+	"github.com/xackery/engine/loader/obj"
+	"github.com/xackery/quail/common"
+)
+
+// Mesh is the geometry of an edited file, with triangles naming their material
+type Mesh struct {
+	Vertices  []common.Vertex
+	Triangles []common.Triangle
+}
+
+// Read loads the mesh of an OBJ or glTF file
+func Read(path string) (*Mesh, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".obj":
+		return ReadOBJ(path)
+	case ".gltf", ".glb":
+		return ReadGLTF(path)
+	}
+	return nil, fmt.Errorf("unsupported mesh file %s", filepath.Base(path))
+}
+
+// ReadOBJ loads an OBJ file, adding a vertex per distinct position, uv and normal
+// combination and splitting polygons into triangle fans
+func ReadOBJ(path string) (*Mesh, error) {
+	dec, err := obj.Decode(path, "")
+	if err != nil {
+		return nil, fmt.Errorf("obj decode: %w", err)
+	}
+	m := &Mesh{}
+	vertices := make(map[[3]int]uint32)
+	index := func(face obj.Face, corner int) uint32 {
+		key := [3]int{face.Vertices[corner], face.Uvs[corner], face.Normals[corner]}
+		i, ok := vertices[key]
+		if ok {
+			return i
+		}
+		v := common.Vertex{}
+		if p := key[0] * 3; p >= 0 && p+2 < len(dec.Vertices) {
+			v.Position = common.Vector3{X: dec.Vertices[p], Y: dec.Vertices[p+1], Z: dec.Vertices[p+2]}
+		}
+		if t := key[1] * 2; t >= 0 && t+1 < len(dec.Uvs) {
+			v.Uv = common.Vector2{X: dec.Uvs[t], Y: dec.Uvs[t+1]}
+		}
+		if n := key[2] * 3; n >= 0 && n+2 < len(dec.Normals) {
+			v.Normal = common.Vector3{X: dec.Normals[n], Y: dec.Normals[n+1], Z: dec.Normals[n+2]}
+		}
+		i = uint32(len(m.Vertices))
+		m.Vertices = append(m.Vertices, v)
+		vertices[key] = i
+		return i
+	}
+	for _, object := range dec.Objects {
+		for _, face := range object.Faces {
+			for corner := 2; corner < len(face.Vertices); corner++ {
+				m.Triangles = append(m.Triangles, common.Triangle{
+					Index:        common.UIndex3{X: index(face, 0), Y: index(face, corner-1), Z: index(face, corner)},
+					MaterialName: face.Material,
+				})
+			}
+		}
+	}
+	return m, nil
+}
+
+// ReadGLTF loads the triangle primitives of every mesh of a glTF or glb file
+func ReadGLTF(path string) (*Mesh, error) {
+	var g *gltf.GLTF
+	var err error
+	if strings.ToLower(filepath.Ext(path)) == ".glb" {
+		g, err = gltf.ParseBin(path)
+	} else {
+		g, err = gltf.ParseJSON(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gltf parse: %w", err)
+	}
+
+	m := &Mesh{}
+	for mi, meshData := range g.Meshes {
+		node, err := g.LoadMesh(mi)
+		if err != nil {
+			return nil, fmt.Errorf("gltf mesh %d: %w", mi, err)
+		}
+		// primitives load as children of the mesh node, unless there is only one
+		graphics := node.GetNode().Children()
+		if len(meshData.Primitives) == 1 {
+			graphics = []core.INode{node}
+		}
+		for pi, p := range meshData.Primitives {
+			if pi >= len(graphics) {
+				break
+			}
+			gr, ok := graphics[pi].(*graphic.Mesh)
+			if !ok {
+				continue
+			}
+			materialName := ""
+			if p.Material != nil && *p.Material < len(g.Materials) {
+				materialName = g.Materials[*p.Material].Name
+			}
+			m.addGeometry(gr.GetGeometry(), materialName)
+		}
+		node.Dispose()
+	}
+	return m, nil
+}
+
+// addGeometry appends the vertices and triangles of geom, all using materialName
+func (m *Mesh) addGeometry(geom *geometry.Geometry, materialName string) {
+	positions := attribute(geom, gls.VertexPosition)
+	normals := attribute(geom, gls.VertexNormal)
+	uvs := attribute(geom, gls.VertexTexcoord)
+
+	base := uint32(len(m.Vertices))
+	count := len(positions) / 3
+	for i := 0; i < count; i++ {
+		v := common.Vertex{Position: common.Vector3{X: positions[i*3], Y: positions[i*3+1], Z: positions[i*3+2]}}
+		if len(normals) >= (i+1)*3 {
+			v.Normal = common.Vector3{X: normals[i*3], Y: normals[i*3+1], Z: normals[i*3+2]}
+		}
+		if len(uvs) >= (i+1)*2 {
+			v.Uv = common.Vector2{X: uvs[i*2], Y: uvs[i*2+1]}
+		}
+		m.Vertices = append(m.Vertices, v)
+	}
+
+	indices := geom.Indices()
+	if len(indices) == 0 {
+		for i := 0; i+2 < count; i += 3 {
+			indices = append(indices, uint32(i), uint32(i+1), uint32(i+2))
+		}
+	}
+	for i := 0; i+2 < len(indices); i += 3 {
+		m.Triangles = append(m.Triangles, common.Triangle{
+			Index:        common.UIndex3{X: base + indices[i], Y: base + indices[i+1], Z: base + indices[i+2]},
+			MaterialName: materialName,
+		})
+	}
+}
+
+// attribute returns the values of an attribute, which may be interleaved with others
+func attribute(geom *geometry.Geometry, atype gls.AttribType) []float32 {
+	vbo := geom.VBO(atype)
+	if vbo == nil {
+		return nil
+	}
+	size := int(vbo.Attrib(atype).NumElements)
+	stride := vbo.Stride()
+	offset := vbo.AttribOffset(atype)
+	buffer := *vbo.Buffer()
+	values := []float32{}
+	for i := offset; i+size <= len(buffer); i += stride {
+		values = append(values, buffer[i:i+size]...)
+	}
+	return values
+}
+
+// Apply returns a copy of target with the geometry of m. Materials are matched to
+// the target's by name ignoring case, and bones and particles are kept
+func Apply(target *common.Model, m *Mesh) (*common.Model, error) {
+	out := *target
+	out.Vertices = m.Vertices
+	out.Triangles = make([]common.Triangle, len(m.Triangles))
+
+	materials := make(map[string]string)
+	for _, mat := range target.Materials {
+		materials[strings.ToLower(mat.Name)] = mat.Name
+	}
+	missing := []string{}
+	for i, tri := range m.Triangles {
+		name, ok := materials[strings.ToLower(tri.MaterialName)]
+		if !ok {
+			if !contains(missing, fmt.Sprintf("%q", tri.MaterialName)) {
+				missing = append(missing, fmt.Sprintf("%q", tri.MaterialName))
+			}
+			name = tri.MaterialName
+		}
+		tri.MaterialName = name
+		out.Triangles[i] = tri
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("materials not in %s: %s", target.Header.Name, strings.Join(missing, ", "))
+	}
+	return &out, nil
+}
+
+// Validate checks model can be encoded, reporting every problem found
+func Validate(model *common.Model) error {
+	problems := []string{}
+	if len(model.Vertices) == 0 {
+		problems = append(problems, "no vertices")
+	}
+	if len(model.Triangles) == 0 {
+		problems = append(problems, "no triangles")
+	}
+	materials := make(map[string]bool)
+	for _, mat := range model.Materials {
+		materials[mat.Name] = true
+	}
+	for i, v := range model.Vertices {
+		if !finite(v.Position.X, v.Position.Y, v.Position.Z, v.Normal.X, v.Normal.Y, v.Normal.Z, v.Uv.X, v.Uv.Y) {
+			problems = append(problems, fmt.Sprintf("vertex %d is not a finite number", i))
+			break
+		}
+	}
+	for i, tri := range model.Triangles {
+		count := uint32(len(model.Vertices))
+		if tri.Index.X >= count || tri.Index.Y >= count || tri.Index.Z >= count {
+			problems = append(problems, fmt.Sprintf("triangle %d indexes a vertex past %d", i, count))
+			break
+		}
+	}
+	for i, tri := range model.Triangles {
+		if !materials[tri.MaterialName] {
+			problems = append(problems, fmt.Sprintf("triangle %d uses unknown material %q", i, tri.MaterialName))
+			break
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s: %s", model.Header.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func finite(values ...float32) bool {
+	for _, v := range values {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, value string) bool {
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xackery/quail/common"
+)
+
+func TestReadOBJ(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "box.obj")
+	data := "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nvt 0 0\nvt 1 0\nvt 1 1\nvt 0 1\nusemtl Wood\nf 1/1 2/2 3/3 4/4\n"
+	err := os.WriteFile(path, []byte(data), 0644)
+	if err != nil {
+		t.Fatalf("write: %s", err)
+	}
+	m, err := ReadOBJ(path)
+	if err != nil {
+		t.Fatalf("read: %s", err)
+	}
+	if len(m.Vertices) != 4 || len(m.Triangles) != 2 {
+		t.Fatalf("%d vertices %d triangles, expected 4 and 2", len(m.Vertices), len(m.Triangles))
+	}
+	if m.Vertices[2].Uv.X != 1 || m.Vertices[2].Uv.Y != 1 {
+		t.Fatalf("vertex 2 uv %v", m.Vertices[2].Uv)
+	}
+
+	target := &common.Model{
+		Header:    &common.Header{Name: "box"},
+		Materials: []*common.Material{{Name: "wood"}},
+	}
+	model, err := Apply(target, m)
+	if err != nil {
+		t.Fatalf("apply: %s", err)
+	}
+	if model.Triangles[0].MaterialName != "wood" {
+		t.Fatalf("material %s, expected wood", model.Triangles[0].MaterialName)
+	}
+	err = Validate(model)
+	if err != nil {
+		t.Fatalf("validate: %s", err)
+	}
+
+	_, err = Apply(&common.Model{Header: &common.Header{Name: "box"}}, m)
+	if err == nil {
+		t.Fatalf("expected missing material error")
+	}
+	model.Triangles[1].Index.Z = 9
+	if Validate(model) == nil {
+		t.Fatalf("expected out of range index error")
+	}
+}
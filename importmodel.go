@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail-view/importer"
+	"github.com/xackery/quail/common"
+	"github.com/xackery/quail/quail"
+)
+
+// importModel maps the last opened OBJ or glTF model onto the archive model of the
+// same name, or the focused one, and writes the archive with it as a new eqg
+func (gv *g3nView) importModel() error {
+	if gv.archivePath == "" {
+		return fmt.Errorf("open an eqg archive to import into")
+	}
+	ext := strings.ToLower(filepath.Ext(gv.archivePath))
+	if ext != ".eqg" {
+		return fmt.Errorf("only eqg archives can be written, %s is %s", filepath.Base(gv.archivePath), ext)
+	}
+	path := ""
+	for i := len(gv.modelPaths) - 1; i >= 0 && path == ""; i-- {
+		switch strings.ToLower(filepath.Ext(gv.modelPaths[i])) {
+		case ".obj", ".gltf", ".glb":
+			path = gv.modelPaths[i]
+		}
+	}
+	if path == "" {
+		return fmt.Errorf("open an obj or gltf model to import")
+	}
+
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	var target *common.Model
+	for name, model := range gv.archiveModels {
+		if strings.EqualFold(name, base) {
+			target = model
+			break
+		}
+	}
+	if target == nil {
+		target = gv.archiveModels[gv.focusName]
+	}
+	if target == nil {
+		return fmt.Errorf("name %s after an archive model, or focus the model to replace", filepath.Base(path))
+	}
+	// quail's writer drops vertex weights, an imported rig would no longer deform
+	if len(target.Bones) > 0 {
+		return fmt.Errorf("%s is rigged, importing over rigged models is not supported", target.Header.Name)
+	}
+
+	m, err := importer.Read(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", filepath.Base(path), err)
+	}
+	model, err := importer.Apply(target, m)
+	if err != nil {
+		return fmt.Errorf("apply: %w", err)
+	}
+	err = importer.Validate(model)
+	if err != nil {
+		return fmt.Errorf("validate: %w", err)
+	}
+
+	// the archive is read again so the opened one is left untouched
+	q := &quail.Quail{}
+	err = q.PfsRead(gv.archivePath)
+	if err != nil {
+		return fmt.Errorf("pfs read: %w", err)
+	}
+	for i, existing := range q.Models {
+		if existing.Header.Name == model.Header.Name {
+			q.Models[i] = model
+		}
+	}
+	out, err := editedPath(gv.archivePath)
+	if err != nil {
+		return err
+	}
+	err = q.PfsWrite(uint32(model.Version), 1, out)
+	if err != nil {
+		return fmt.Errorf("pfs write: %w", err)
+	}
+	gv.ed.Show(fmt.Sprintf("Wrote %s with %d vertices and %d triangles from %s to %s",
+		model.Header.Name, len(model.Vertices), len(model.Triangles), filepath.Base(path), out))
+	return nil
+}
+
+// editedPath returns the first of archive_edited.eqg, archive_edited2.eqg and so on
+// that does not exist yet, so earlier imports are never overwritten
+func editedPath(archivePath string) (string, error) {
+	base := strings.TrimSuffix(archivePath, filepath.Ext(archivePath)) + "_edited"
+	for i := 1; i < 1000; i++ {
+		out := base + ".eqg"
+		if i > 1 {
+			out = fmt.Sprintf("%s%d.eqg", base, i)
+		}
+		_, err := os.Stat(out)
+		if os.IsNotExist(err) {
+			return out, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("stat %s: %w", out, err)
+		}
+	}
+	return "", fmt.Errorf("no free name for %s.eqg", base)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/mesh"
+)
+
+type InfoPanel struct {
+	gui.Panel
+	list *gui.List
+}
+
+func NewInfoPanel(width, height float32) *InfoPanel {
+
+	ip := new(InfoPanel)
+	ip.Panel.Initialize(ip, width, height)
+	ip.SetBorders(1, 1, 1, 1)
+	ip.SetPaddings(4, 4, 4, 4)
+	ip.SetColor4(&math32.Color4{R: 1, G: 1, B: 1, A: 0.85})
+	ip.SetVisible(false)
+	ip.SetBounded(false)
+	ip.SetPosition(10, 30)
+
+	// Set vertical box layout for the whole panel
+	l := gui.NewVBoxLayout()
+	l.SetSpacing(4)
+	ip.SetLayout(l)
+
+	ip.list = gui.NewVList(0, 0)
+	ip.list.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 1, AlignH: gui.AlignWidth})
+	ip.Add(ip.list)
+
+	return ip
+}
+
+// SetLines replaces the text shown in the panel
+func (ip *InfoPanel) SetLines(lines []string) {
+	ip.list.Clear()
+	for _, line := range lines {
+		ip.list.Add(gui.NewLabel(line))
+	}
+}
+
+// buildInfoPanel creates the model info overlay
+func (gv *g3nView) buildInfoPanel() {
+	gv.ip = NewInfoPanel(320, 360)
+	gv.ip.SetVisible(gv.settings.ViewInfo)
+	gv.scene.Add(gv.ip)
+	gv.refreshInfo()
+}
+
+// refreshInfo shows the statistics of the focused model
+func (gv *g3nView) refreshInfo() {
+	model := gv.archiveModels[gv.focusName]
+	if gv.archiveQuail == nil || model == nil {
+		gv.ip.SetLines([]string{"No model focused", "Pick one from the Center On menu"})
+		return
+	}
+
+	info := mesh.Info(gv.archiveQuail, model)
+	lines := []string{
+		info.Name,
+		fmt.Sprintf("Vertices: %d", info.Vertices),
+		fmt.Sprintf("Triangles: %d", info.Triangles),
+		fmt.Sprintf("Size: %.2f x %.2f x %.2f", info.Size[0], info.Size[1], info.Size[2]),
+		fmt.Sprintf("Bones: %d", info.Bones),
+		fmt.Sprintf("Materials: %d", len(info.Materials)),
+	}
+	for _, mat := range info.Materials {
+		lines = append(lines, fmt.Sprintf("  %s (%s)", mat.Name, mat.Shader))
+		for _, tex := range mat.Textures {
+			if tex.Width == 0 {
+				lines = append(lines, fmt.Sprintf("    %s %s", tex.Name, tex.Format))
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("    %s %dx%d %s", tex.Name, tex.Width, tex.Height, tex.Format))
+		}
+	}
+
+	// every animation of the archive plays on rigged models
+	if info.Bones > 0 {
+		names := []string{}
+		seen := make(map[string]bool)
+		for _, anim := range gv.archiveQuail.Animations {
+			if seen[anim.Header.Name] {
+				continue
+			}
+			seen[anim.Header.Name] = true
+			names = append(names, anim.Header.Name)
+		}
+		lines = append(lines, fmt.Sprintf("Animations: %d", len(names)))
+		for _, name := range names {
+			lines = append(lines, "  "+name)
+		}
+	}
+	gv.ip.SetLines(lines)
+}
@@ -0,0 +1,128 @@
+// Package fixture builds synthetic archives in code, so tests run without EQ game data
+package fixture
+
+import (
+	"github.com/xackery/quail/common"
+	"github.com/xackery/quail/quail"
+)
+
+// Materials of Cube, one per texture format plus an untextured one. The png texture
+// is embedded in its material property, the others are archive files
+var Materials = []string{"png", "bmp", "dds", "plain"}
+
+// Archive returns an archive with a static cube, a cube rigged to a three bone chain,
+// a keyframed animation of the chain and the textures of the cubes
+func Archive() *quail.Quail {
+	rigged := Cube("rigged")
+	rigged.Bones = BoneChain("rigged", 3, 0.5)
+	return &quail.Quail{
+		Models:     []*common.Model{Cube("box"), rigged},
+		Animations: []*common.Animation{Animation("c01", rigged.Bones, 4)},
+		Textures: map[string][]byte{
+			"checker.bmp": BMP(),
+			"checker.dds": DDS(),
+		},
+	}
+}
+
+// Cube returns a unit cube centered on the origin with 24 vertices, so each face has
+// its own normals and uvs. Faces take Materials in turn
+func Cube(name string) *common.Model {
+	model := &common.Model{
+		Header:   &common.Header{Name: name},
+		FileType: "mod",
+		Version:  1,
+		Materials: []*common.Material{
+			texturedMaterial("png", "checker.png", PNG()),
+			texturedMaterial("bmp", "checker.bmp", nil),
+			texturedMaterial("dds", "checker.dds", nil),
+			{Name: "plain", ShaderName: "Opaque_MaxCB1.fx"},
+		},
+	}
+	faces := []struct {
+		normal, u, v common.Vector3
+	}{
+		{common.Vector3{Z: 1}, common.Vector3{X: 1}, common.Vector3{Y: 1}},
+		{common.Vector3{X: 1}, common.Vector3{Z: -1}, common.Vector3{Y: 1}},
+		{common.Vector3{Y: 1}, common.Vector3{X: 1}, common.Vector3{Z: -1}},
+		{common.Vector3{Z: -1}, common.Vector3{X: -1}, common.Vector3{Y: 1}},
+		{common.Vector3{X: -1}, common.Vector3{Z: 1}, common.Vector3{Y: 1}},
+		{common.Vector3{Y: -1}, common.Vector3{X: 1}, common.Vector3{Z: 1}},
+	}
+	for i, f := range faces {
+		base := uint32(len(model.Vertices))
+		for _, corner := range [][2]float32{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+			su, sv := corner[0]-0.5, corner[1]-0.5
+			model.Vertices = append(model.Vertices, common.Vertex{
+				Position: common.Vector3{
+					X: f.normal.X*0.5 + f.u.X*su + f.v.X*sv,
+					Y: f.normal.Y*0.5 + f.u.Y*su + f.v.Y*sv,
+					Z: f.normal.Z*0.5 + f.u.Z*su + f.v.Z*sv,
+				},
+				Normal: f.normal,
+				Tint:   common.RGBA{R: 255, G: 255, B: 255, A: 255},
+				Uv:     common.Vector2{X: corner[0], Y: corner[1]},
+			})
+		}
+		material := Materials[i%len(Materials)]
+		model.Triangles = append(model.Triangles,
+			common.Triangle{Index: common.UIndex3{X: base, Y: base + 1, Z: base + 2}, MaterialName: material},
+			common.Triangle{Index: common.UIndex3{X: base, Y: base + 2, Z: base + 3}, MaterialName: material},
+		)
+	}
+	return model
+}
+
+// texturedMaterial returns a material whose diffuse texture is file, with data embedded
+// in the property when set
+func texturedMaterial(name string, file string, data []byte) *common.Material {
+	return &common.Material{
+		Name:       name,
+		ShaderName: "Opaque_MaxCB1.fx",
+		Properties: []*common.MaterialProperty{
+			{Name: "e_TextureDiffuse0", Category: 2, Value: file, Data: data},
+		},
+	}
+}
+
+// BoneChain returns count bones named prefix0, prefix1 and so on, each the only child
+// of the previous one and length above it
+func BoneChain(prefix string, count int, length float32) []common.Bone {
+	bones := make([]common.Bone, count)
+	for i := range bones {
+		bone := common.Bone{
+			Name:     boneName(prefix, i),
+			Next:     -1,
+			Rotation: common.Quad4{W: 1},
+			Scale:    common.Vector3{X: 1, Y: 1, Z: 1},
+		}
+		if i > 0 {
+			bone.Pivot = common.Vector3{Y: length}
+		}
+		if i < count-1 {
+			bone.ChildrenCount = 1
+			bone.ChildIndex = int32(i + 1)
+		}
+		bones[i] = bone
+	}
+	return bones
+}
+
+// Animation returns an animation bending every bone of bones a quarter turn around Z
+// over frames keyframes, 100 milliseconds apart
+func Animation(name string, bones []common.Bone, frames int) *common.Animation {
+	animation := &common.Animation{Header: &common.Header{Name: name}, Name: name}
+	for _, bone := range bones {
+		track := &common.BoneAnimation{Name: bone.Name, FrameCount: uint32(frames)}
+		for i := 0; i < frames; i++ {
+			track.Frames = append(track.Frames, &common.BoneAnimationFrame{
+				Milliseconds: uint32(i * 100),
+				Translation:  bone.Pivot,
+				Rotation:     zRotation(float64(i) / float64(frames-1) * 90),
+				Scale:        common.Vector3{X: 1, Y: 1, Z: 1},
+			})
+		}
+		animation.Bones = append(animation.Bones, track)
+	}
+	return animation
+}
@@ -0,0 +1,47 @@
+package fixture
+
+import (
+	"bytes"
+	"image"
+	_ "image/png"
+	"testing"
+
+	"github.com/malashin/dds"
+	"github.com/sergeymakinen/go-bmp"
+)
+
+func TestTextures(t *testing.T) {
+	decoders := map[string]func([]byte) (image.Image, error){
+		"png": func(data []byte) (image.Image, error) {
+			img, _, err := image.Decode(bytes.NewReader(data))
+			return img, err
+		},
+		"bmp": func(data []byte) (image.Image, error) { return bmp.Decode(bytes.NewReader(data)) },
+		"dds": func(data []byte) (image.Image, error) { return dds.Decode(bytes.NewReader(data)) },
+	}
+	encoded := map[string][]byte{"png": PNG(), "bmp": BMP(), "dds": DDS()}
+	want := Checker()
+	for name, decode := range decoders {
+		img, err := decode(encoded[name])
+		if err != nil {
+			t.Fatalf("%s decode: %s", name, err)
+		}
+		r, g, b, _ := img.At(1, 0).RGBA()
+		wr, wg, wb, _ := want.At(1, 0).RGBA()
+		if r != wr || g != wg || b != wb {
+			t.Fatalf("%s pixel 1,0 is %d %d %d, expected %d %d %d", name, r>>8, g>>8, b>>8, wr>>8, wg>>8, wb>>8)
+		}
+	}
+}
+
+func TestCube(t *testing.T) {
+	cube := Cube("box")
+	if len(cube.Vertices) != 24 || len(cube.Triangles) != 12 {
+		t.Fatalf("%d vertices %d triangles, expected 24 and 12", len(cube.Vertices), len(cube.Triangles))
+	}
+	for _, tri := range cube.Triangles {
+		if tri.Index.X >= 24 || tri.Index.Y >= 24 || tri.Index.Z >= 24 {
+			t.Fatalf("triangle %v out of range", tri.Index)
+		}
+	}
+}
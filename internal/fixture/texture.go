@@ -0,0 +1,89 @@
+package fixture
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+
+	"github.com/sergeymakinen/go-bmp"
+	"github.com/xackery/quail/common"
+)
+
+// checkerSize is the width and height of the checker textures, a multiple of the
+// 4 pixel blocks of compressed dds
+const checkerSize = 4
+
+// Checker returns a red and white checker image
+func Checker() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, checkerSize, checkerSize))
+	for y := 0; y < checkerSize; y++ {
+		for x := 0; x < checkerSize; x++ {
+			c := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+			if (x+y)%2 == 0 {
+				c = color.RGBA{R: 200, G: 30, B: 30, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// PNG returns Checker encoded as png
+func PNG() []byte {
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, Checker())
+	if err != nil {
+		panic(fmt.Sprintf("encode png: %s", err))
+	}
+	return buf.Bytes()
+}
+
+// BMP returns Checker encoded as bmp
+func BMP() []byte {
+	buf := &bytes.Buffer{}
+	err := bmp.Encode(buf, Checker())
+	if err != nil {
+		panic(fmt.Sprintf("encode bmp: %s", err))
+	}
+	return buf.Bytes()
+}
+
+// DDS returns Checker as an uncompressed 32 bit dds
+func DDS() []byte {
+	const (
+		headerSize      = 124
+		pixelFormatSize = 32
+		flagsTexture    = 0x1 | 0x2 | 0x4 | 0x1000 // caps, height, width and pixel format
+		pixelFlags      = 0x1 | 0x40               // alpha pixels and rgb
+		capsTexture     = 0x1000
+	)
+	buf := &bytes.Buffer{}
+	buf.WriteString("DDS ")
+	header := []uint32{headerSize, flagsTexture, checkerSize, checkerSize, checkerSize * 4, 0, 0}
+	header = append(header, make([]uint32, 11)...) // reserved
+	header = append(header, pixelFormatSize, pixelFlags, 0, 32, 0x00ff0000, 0x0000ff00, 0x000000ff, 0xff000000)
+	header = append(header, capsTexture, 0, 0, 0, 0)
+	binary.Write(buf, binary.LittleEndian, header)
+
+	// pixels are stored as bgra
+	img := Checker()
+	for i := 0; i < len(img.Pix); i += 4 {
+		buf.Write([]byte{img.Pix[i+2], img.Pix[i+1], img.Pix[i], img.Pix[i+3]})
+	}
+	return buf.Bytes()
+}
+
+// boneName returns the name of bone index of a chain
+func boneName(prefix string, index int) string {
+	return fmt.Sprintf("%s%d", prefix, index)
+}
+
+// zRotation returns a rotation of degrees around the Z axis
+func zRotation(degrees float64) common.Quad4 {
+	half := degrees * math.Pi / 360
+	return common.Quad4{Z: float32(math.Sin(half)), W: float32(math.Cos(half))}
+}
@@ -0,0 +1,139 @@
+package lighting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/light"
+	"github.com/xackery/engine/math32"
+)
+
+const (
+	// TypeAmbient lights every surface evenly
+	TypeAmbient = "ambient"
+	// TypePoint emits from a position, scaled by scene width
+	TypePoint = "point"
+	// TypeDirectional shines along a direction
+	TypeDirectional = "directional"
+)
+
+// Light describes one light of a rig
+type Light struct {
+	Name      string     `json:"name"`
+	Type      string     `json:"type"`
+	Color     [3]float32 `json:"color"`
+	Intensity float32    `json:"intensity"`
+	// Position is the direction of a directional light, or the position of a
+	// point light in multiples of the scene width
+	Position [3]float32 `json:"position"`
+}
+
+// Rig is a named set of lights
+type Rig struct {
+	Name   string   `json:"name"`
+	Lights []*Light `json:"lights"`
+}
+
+// Presets returns the built in rigs
+func Presets() []*Rig {
+	return []*Rig{
+		{Name: "default", Lights: []*Light{
+			{Name: "ambient", Type: TypeAmbient, Color: [3]float32{1, 1, 1}, Intensity: 2},
+			{Name: "front", Type: TypePoint, Color: [3]float32{1, 1, 1}, Intensity: 2.5, Position: [3]float32{0, 0, 0.5}},
+			{Name: "side", Type: TypePoint, Color: [3]float32{1, 1, 1}, Intensity: 2.5, Position: [3]float32{0.5, 0, 0}},
+			{Name: "top", Type: TypePoint, Color: [3]float32{1, 1, 1}, Intensity: 2.5, Position: [3]float32{0, 0.5, 0}},
+			{Name: "sun", Type: TypeDirectional, Color: [3]float32{1, 1, 1}, Intensity: 1, Position: [3]float32{0, 0.45, 0.9}},
+		}},
+		{Name: "studio", Lights: []*Light{
+			{Name: "ambient", Type: TypeAmbient, Color: [3]float32{1, 1, 1}, Intensity: 0.4},
+			{Name: "key", Type: TypeDirectional, Color: [3]float32{1, 0.97, 0.92}, Intensity: 1.2, Position: [3]float32{0.6, 0.6, 0.5}},
+			{Name: "fill", Type: TypeDirectional, Color: [3]float32{0.85, 0.9, 1}, Intensity: 0.5, Position: [3]float32{-0.7, 0.3, 0.6}},
+			{Name: "rim", Type: TypeDirectional, Color: [3]float32{1, 1, 1}, Intensity: 0.8, Position: [3]float32{0, 0.6, -0.8}},
+		}},
+		{Name: "flat", Lights: []*Light{
+			{Name: "ambient", Type: TypeAmbient, Color: [3]float32{1, 1, 1}, Intensity: 1},
+		}},
+		{Name: "in-game", Lights: []*Light{
+			{Name: "ambient", Type: TypeAmbient, Color: [3]float32{1, 0.95, 0.85}, Intensity: 0.6},
+			{Name: "sun", Type: TypeDirectional, Color: [3]float32{1, 0.95, 0.85}, Intensity: 0.8, Position: [3]float32{0.2, 0.95, 0.25}},
+		}},
+		{Name: "night", Lights: []*Light{
+			{Name: "ambient", Type: TypeAmbient, Color: [3]float32{0.4, 0.45, 0.7}, Intensity: 0.25},
+			{Name: "moon", Type: TypeDirectional, Color: [3]float32{0.6, 0.7, 1}, Intensity: 0.35, Position: [3]float32{-0.4, 0.85, -0.35}},
+		}},
+	}
+}
+
+// Clone returns a deep copy of the rig
+func (r *Rig) Clone() *Rig {
+	out := &Rig{Name: r.Name}
+	for _, l := range r.Lights {
+		nl := *l
+		out.Lights = append(out.Lights, &nl)
+	}
+	return out
+}
+
+// Generate creates engine lights for the rig, scaling point lights by sceneWidth.
+// Point lights are left out with skipPoint, zones bring their own
+func (r *Rig) Generate(sceneWidth float32, skipPoint bool) ([]core.INode, error) {
+	nodes := make([]core.INode, 0, len(r.Lights))
+	for _, l := range r.Lights {
+		color := &math32.Color{R: l.Color[0], G: l.Color[1], B: l.Color[2]}
+		switch l.Type {
+		case TypeAmbient:
+			nodes = append(nodes, light.NewAmbient(color, l.Intensity))
+		case TypePoint:
+			if skipPoint {
+				continue
+			}
+			pointLight := light.NewPoint(color, l.Intensity*sceneWidth)
+			pointLight.SetPosition(l.Position[0]*sceneWidth, l.Position[1]*sceneWidth, l.Position[2]*sceneWidth)
+			nodes = append(nodes, pointLight)
+		case TypeDirectional:
+			dir := light.NewDirectional(color, l.Intensity)
+			dir.SetPosition(l.Position[0], l.Position[1], l.Position[2])
+			nodes = append(nodes, dir)
+		default:
+			return nil, fmt.Errorf("light %s: unknown type %s", l.Name, l.Type)
+		}
+	}
+	return nodes, nil
+}
+
+// Load reads saved rigs from path, returning none if the file does not exist
+func Load(path string) ([]*Rig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read: %w", err)
+	}
+	rigs := []*Rig{}
+	err = json.Unmarshal(data, &rigs)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+	return rigs, nil
+}
+
+// Save writes rigs to path
+func Save(path string, rigs []*Rig) error {
+	data, err := json.MarshalIndent(rigs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package lighting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xackery/engine/light"
+)
+
+func TestPresets(t *testing.T) {
+	names := make(map[string]bool)
+	for _, rig := range Presets() {
+		if names[rig.Name] {
+			t.Fatalf("duplicate preset %s", rig.Name)
+		}
+		names[rig.Name] = true
+		nodes, err := rig.Generate(2, false)
+		if err != nil {
+			t.Fatalf("%s: %s", rig.Name, err.Error())
+		}
+		if len(nodes) != len(rig.Lights) {
+			t.Fatalf("%s: %d lights, expected %d", rig.Name, len(nodes), len(rig.Lights))
+		}
+	}
+}
+
+func TestRigGenerate(t *testing.T) {
+	tests := []struct {
+		name      string
+		lights    []*Light
+		skipPoint bool
+		count     int
+		isErr     bool
+	}{
+		{name: "point", lights: []*Light{{Type: TypePoint, Intensity: 1, Position: [3]float32{0, 1, 0}}}, count: 1},
+		{name: "skip point", lights: []*Light{{Type: TypeAmbient}, {Type: TypePoint}}, skipPoint: true, count: 1},
+		{name: "unknown type", lights: []*Light{{Name: "spot", Type: "spot"}}, isErr: true},
+	}
+	for _, tt := range tests {
+		rig := &Rig{Name: tt.name, Lights: tt.lights}
+		nodes, err := rig.Generate(4, tt.skipPoint)
+		if tt.isErr {
+			if err == nil {
+				t.Fatalf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err.Error())
+		}
+		if len(nodes) != tt.count {
+			t.Fatalf("%s: %d lights, expected %d", tt.name, len(nodes), tt.count)
+		}
+		if point, ok := nodes[0].(*light.Point); ok && point.Position().Y != 4 {
+			t.Fatalf("%s: point light at %v, expected it scaled by scene width", tt.name, point.Position())
+		}
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.json")
+	err := os.WriteFile(corrupt, []byte("[{"), 0644)
+	if err != nil {
+		t.Fatalf("write: %s", err.Error())
+	}
+	saved := filepath.Join(dir, "quail-view", "lighting.json")
+	err = Save(saved, []*Rig{Presets()[1].Clone()})
+	if err != nil {
+		t.Fatalf("save: %s", err.Error())
+	}
+
+	tests := []struct {
+		name  string
+		path  string
+		count int
+		isErr bool
+	}{
+		{name: "missing", path: filepath.Join(dir, "missing.json")},
+		{name: "corrupt", path: corrupt, isErr: true},
+		{name: "saved", path: saved, count: 1},
+	}
+	for _, tt := range tests {
+		rigs, err := Load(tt.path)
+		if tt.isErr {
+			if err == nil {
+				t.Fatalf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err.Error())
+		}
+		if len(rigs) != tt.count {
+			t.Fatalf("%s: %d rigs, expected %d", tt.name, len(rigs), tt.count)
+		}
+	}
+
+	rigs, err := Load(saved)
+	if err != nil {
+		t.Fatalf("load: %s", err.Error())
+	}
+	studio := Presets()[1]
+	if rigs[0].Name != studio.Name || len(rigs[0].Lights) != len(studio.Lights) || *rigs[0].Lights[1] != *studio.Lights[1] {
+		t.Fatalf("rig not restored, got %+v", rigs[0])
+	}
+}
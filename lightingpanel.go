@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/xackery/engine/app"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/lighting"
+	"github.com/xackery/quail-view/settings"
+)
+
+type LightingPanel struct {
+	gui.Panel
+	presets  []*lighting.Rig
+	rig      *lighting.Rig
+	ddPreset *gui.DropDown
+	ddLight  *gui.DropDown
+	sliders  map[string]*gui.Slider
+	bsave    *gui.Button
+	bclose   *gui.Button
+	updating bool
+}
+
+// lightingSliders are the editable values of the selected light, in display order
+var lightingSliders = []string{"Red", "Green", "Blue", "Intensity", "X", "Y", "Z"}
+
+func NewLightingPanel(width, height float32) *LightingPanel {
+
+	lp := new(LightingPanel)
+	lp.Panel.Initialize(lp, width, height)
+	lp.SetBorders(2, 2, 2, 2)
+	lp.SetPaddings(4, 4, 4, 4)
+	lp.SetColor(math32.NewColor("White"))
+	lp.SetVisible(false)
+	lp.SetBounded(false)
+	lp.sliders = make(map[string]*gui.Slider)
+
+	// Set vertical box layout for the whole panel
+	l := gui.NewVBoxLayout()
+	l.SetSpacing(4)
+	lp.SetLayout(l)
+
+	lp.Add(gui.NewLabel("Preset"))
+	lp.ddPreset = gui.NewDropDown(width-16, gui.NewImageLabel(""))
+	lp.ddPreset.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		if lp.updating {
+			return
+		}
+		pos := lp.ddPreset.SelectedPos()
+		if pos < 0 || pos >= len(lp.presets) {
+			return
+		}
+		lp.Dispatch("OnPreset", lp.presets[pos])
+	})
+	lp.Add(lp.ddPreset)
+
+	lp.Add(gui.NewLabel("Light"))
+	lp.ddLight = gui.NewDropDown(width-16, gui.NewImageLabel(""))
+	lp.ddLight.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		lp.refreshSliders()
+	})
+	lp.Add(lp.ddLight)
+
+	for _, name := range lightingSliders {
+		s := gui.NewHSlider(width-16, 20)
+		s.SetText(name)
+		if name == "Intensity" {
+			s.SetScaleFactor(5)
+		}
+		s.Subscribe(gui.OnChange, lp.onSlider)
+		lp.sliders[name] = s
+		lp.Add(s)
+	}
+
+	// Button container panel
+	bc := gui.NewPanel(0, 0)
+	bcl := gui.NewHBoxLayout()
+	bcl.SetAlignH(gui.AlignWidth)
+	bc.SetLayout(bcl)
+	bc.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 1, AlignH: gui.AlignWidth})
+	lp.Add(bc)
+
+	// Creates Save button
+	lp.bsave = gui.NewButton("Save preset")
+	lp.bsave.SetLayoutParams(&gui.HBoxLayoutParams{Expand: 0, AlignV: gui.AlignCenter})
+	lp.bsave.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		lp.Dispatch("OnSave", nil)
+	})
+	bc.Add(lp.bsave)
+
+	// Creates Close button
+	lp.bclose = gui.NewButton("Close")
+	lp.bclose.SetLayoutParams(&gui.HBoxLayoutParams{Expand: 0, AlignV: gui.AlignCenter})
+	lp.bclose.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		lp.SetVisible(false)
+	})
+	bc.Add(lp.bclose)
+
+	return lp
+}
+
+// Show shows or hide the lighting panel
+func (lp *LightingPanel) Show(show bool) {
+
+	if !show {
+		lp.SetVisible(false)
+		return
+	}
+	lp.SetVisible(true)
+	width, _ := app.App(300, 300, "Lighting").GetSize()
+	lp.SetPosition(float32(width)-lp.Width()-10, 30)
+}
+
+// SetPresets sets the rigs listed in the preset drop down
+func (lp *LightingPanel) SetPresets(presets []*lighting.Rig) {
+	lp.updating = true
+	defer func() { lp.updating = false }()
+
+	lp.presets = presets
+	for lp.ddPreset.Len() > 0 {
+		lp.ddPreset.RemoveAt(0)
+	}
+	for _, rig := range presets {
+		lp.ddPreset.Add(gui.NewImageLabel(rig.Name))
+	}
+}
+
+// SetRig sets the rig being edited
+func (lp *LightingPanel) SetRig(rig *lighting.Rig) {
+	lp.updating = true
+	lp.rig = rig
+	for i, preset := range lp.presets {
+		if preset.Name == rig.Name {
+			lp.ddPreset.SelectPos(i)
+			break
+		}
+	}
+	for lp.ddLight.Len() > 0 {
+		lp.ddLight.RemoveAt(0)
+	}
+	for _, l := range rig.Lights {
+		lp.ddLight.Add(gui.NewImageLabel(fmt.Sprintf("%s (%s)", l.Name, l.Type)))
+	}
+	lp.updating = false
+
+	if len(rig.Lights) > 0 {
+		lp.ddLight.SelectPos(0)
+	}
+	lp.refreshSliders()
+}
+
+// selectedLight returns the light picked in the light drop down
+func (lp *LightingPanel) selectedLight() *lighting.Light {
+	if lp.rig == nil {
+		return nil
+	}
+	pos := lp.ddLight.SelectedPos()
+	if pos < 0 || pos >= len(lp.rig.Lights) {
+		return nil
+	}
+	return lp.rig.Lights[pos]
+}
+
+// refreshSliders shows the values of the selected light
+func (lp *LightingPanel) refreshSliders() {
+	l := lp.selectedLight()
+	if l == nil {
+		return
+	}
+	lp.updating = true
+	defer func() { lp.updating = false }()
+
+	lp.sliders["Red"].SetValue(l.Color[0])
+	lp.sliders["Green"].SetValue(l.Color[1])
+	lp.sliders["Blue"].SetValue(l.Color[2])
+	lp.sliders["Intensity"].SetValue(l.Intensity)
+	// positions are -1 to 1, sliders are 0 to 1
+	lp.sliders["X"].SetValue((l.Position[0] + 1) / 2)
+	lp.sliders["Y"].SetValue((l.Position[1] + 1) / 2)
+	lp.sliders["Z"].SetValue((l.Position[2] + 1) / 2)
+	for _, name := range []string{"X", "Y", "Z"} {
+		lp.sliders[name].SetEnabled(l.Type != lighting.TypeAmbient)
+	}
+}
+
+// onSlider copies slider values into the selected light
+func (lp *LightingPanel) onSlider(evname string, ev interface{}) {
+	if lp.updating {
+		return
+	}
+	l := lp.selectedLight()
+	if l == nil {
+		return
+	}
+	l.Color[0] = lp.sliders["Red"].Value()
+	l.Color[1] = lp.sliders["Green"].Value()
+	l.Color[2] = lp.sliders["Blue"].Value()
+	l.Intensity = lp.sliders["Intensity"].Value()
+	l.Position[0] = lp.sliders["X"].Value()*2 - 1
+	l.Position[1] = lp.sliders["Y"].Value()*2 - 1
+	l.Position[2] = lp.sliders["Z"].Value()*2 - 1
+	lp.Dispatch("OnChange", lp.rig)
+}
+
+// buildLightingPanel creates the lighting panel and its preset name dialog
+func (gv *g3nView) buildLightingPanel() {
+	gv.lp = NewLightingPanel(260, 420)
+	gv.lp.Subscribe("OnPreset", func(evname string, ev interface{}) {
+		rig := ev.(*lighting.Rig).Clone()
+		gv.lp.SetRig(rig)
+		err := gv.applyRig(rig)
+		if err != nil {
+			gv.ed.Show(err.Error())
+		}
+	})
+	gv.lp.Subscribe("OnChange", func(evname string, ev interface{}) {
+		err := gv.applyRig(ev.(*lighting.Rig))
+		if err != nil {
+			gv.ed.Show(err.Error())
+		}
+	})
+	gv.lp.Subscribe("OnSave", func(evname string, ev interface{}) {
+		gv.rigDialog.Show("Preset name", gv.rig.Name)
+	})
+	gv.scene.Add(gv.lp)
+
+	gv.rigDialog = NewNameDialog(300, 100)
+	gv.rigDialog.Subscribe("OnOK", func(evname string, ev interface{}) {
+		name := gv.rigDialog.Name()
+		if name == "" {
+			gv.ed.Show("Preset name is empty")
+			return
+		}
+		err := gv.saveRig(name)
+		if err != nil {
+			gv.ed.Show(err.Error())
+			return
+		}
+		gv.rigDialog.SetVisible(false)
+	})
+	gv.scene.Add(gv.rigDialog)
+
+	gv.refreshRigPresets()
+}
+
+// refreshRigPresets lists built in and user saved rigs in the lighting panel
+func (gv *g3nView) refreshRigPresets() {
+	presets := lighting.Presets()
+	path, err := rigPresetPath()
+	if err == nil {
+		userRigs, err := lighting.Load(path)
+		if err != nil {
+			fmt.Println("Failed to load lighting presets:", err)
+		}
+		presets = append(presets, userRigs...)
+	}
+	gv.lp.SetPresets(presets)
+}
+
+// applyRig replaces the scene lights with the lights of rig
+func (gv *g3nView) applyRig(rig *lighting.Rig) error {
+	nodes, err := rig.Generate(gv.sceneWidth, gv.isZone)
+	if err != nil {
+		return fmt.Errorf("generate lights: %w", err)
+	}
+	for _, node := range gv.rigLights {
+		gv.scene.Remove(node)
+	}
+	gv.rigLights = nodes
+	for _, node := range gv.rigLights {
+		gv.scene.Add(node)
+	}
+	gv.rig = rig
+	return nil
+}
+
+// saveRig saves the current rig as a user preset
+func (gv *g3nView) saveRig(name string) error {
+	path, err := rigPresetPath()
+	if err != nil {
+		return err
+	}
+	userRigs, err := lighting.Load(path)
+	if err != nil {
+		return fmt.Errorf("lighting load: %w", err)
+	}
+
+	rig := gv.rig.Clone()
+	rig.Name = name
+	isNew := true
+	for i, existing := range userRigs {
+		if existing.Name != name {
+			continue
+		}
+		userRigs[i] = rig
+		isNew = false
+		break
+	}
+	if isNew {
+		userRigs = append(userRigs, rig)
+	}
+
+	err = lighting.Save(path, userRigs)
+	if err != nil {
+		return fmt.Errorf("lighting save: %w", err)
+	}
+	gv.rig.Name = name
+	gv.refreshRigPresets()
+	gv.lp.SetRig(gv.rig)
+	fmt.Println("Saved lighting preset", name)
+	return nil
+}
+
+// rigPresetPath returns where user saved lighting rigs are stored
+func rigPresetPath() (string, error) {
+	dir, err := settings.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "lighting.json"), nil
+}
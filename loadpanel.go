@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/xackery/engine/app"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/math32"
+)
+
+type LoadPanel struct {
+	gui.Panel
+	label *gui.Label
+	track *gui.Panel
+	bar   *gui.Panel
+}
+
+func NewLoadPanel(width, height float32) *LoadPanel {
+
+	lp := new(LoadPanel)
+	lp.Panel.Initialize(lp, width, height)
+	lp.SetBorders(2, 2, 2, 2)
+	lp.SetPaddings(4, 4, 4, 4)
+	lp.SetColor(math32.NewColor("White"))
+	lp.SetVisible(false)
+	lp.SetBounded(false)
+
+	// Set vertical box layout for the whole panel
+	l := gui.NewVBoxLayout()
+	l.SetSpacing(4)
+	lp.SetLayout(l)
+
+	lp.label = gui.NewLabel("")
+	lp.Add(lp.label)
+
+	// the bar grows inside the track as loading progresses
+	lp.track = gui.NewPanel(width-16, 14)
+	lp.track.SetBorders(1, 1, 1, 1)
+	lp.track.SetBordersColor(math32.NewColor("Gray"))
+	lp.track.SetColor(math32.NewColor("LightGray"))
+	lp.Add(lp.track)
+
+	lp.bar = gui.NewPanel(0, lp.track.ContentHeight())
+	lp.bar.SetColor(math32.NewColor("SteelBlue"))
+	lp.track.Add(lp.bar)
+
+	return lp
+}
+
+// Show shows or hide the loading panel
+func (lp *LoadPanel) Show(show bool) {
+
+	if !show {
+		lp.SetVisible(false)
+		return
+	}
+	lp.SetVisible(true)
+	width, height := app.App(300, 300, "Loading").GetSize()
+	lp.SetPosition((float32(width)-lp.Width())/2, float32(height)-lp.Height()-10)
+}
+
+// SetProgress shows text and fills fraction of the bar
+func (lp *LoadPanel) SetProgress(text string, fraction float32) {
+	lp.label.SetText(text)
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	lp.bar.SetWidth(lp.track.ContentWidth() * fraction)
+}
+
+// buildLoadPanel creates the archive loading progress bar
+func (gv *g3nView) buildLoadPanel() {
+	gv.loadBar = NewLoadPanel(320, 50)
+	gv.scene.Add(gv.loadBar)
+}
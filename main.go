@@ -5,15 +5,18 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/xackery/quail-view/anim"
-	"github.com/xackery/quail-view/skeleton"
-
-	"github.com/xackery/quail-view/mesh"
+	"github.com/xackery/quail-view/bookmark"
+	"github.com/xackery/quail-view/lighting"
+	"github.com/xackery/quail-view/settings"
+	"github.com/xackery/quail-view/skin"
 
+	"github.com/xackery/quail/common"
 	"github.com/xackery/quail/quail"
 
+	"github.com/xackery/engine/animation"
 	"github.com/xackery/engine/app"
 	"github.com/xackery/engine/camera"
 	"github.com/xackery/engine/core"
@@ -21,8 +24,8 @@ import (
 	"github.com/xackery/engine/graphic"
 	"github.com/xackery/engine/gui"
 	"github.com/xackery/engine/gui/assets/icon"
-	"github.com/xackery/engine/light"
 	"github.com/xackery/engine/loader/collada"
+	"github.com/xackery/engine/loader/gltf"
 	"github.com/xackery/engine/loader/obj"
 	"github.com/xackery/engine/math32"
 	"github.com/xackery/engine/renderer"
@@ -45,28 +48,85 @@ type g3nView struct {
 	*app.Application                // Embedded application object
 	fs               *FileSelect    // File selection dialog
 	ed               *ErrorDialog   // Error dialog
+	nd               *NameDialog    // Name input dialog
 	axes             *helper.Axes   // Axis helper
 	grid             *helper.Grid   // Grid helper
-	viewAxes         bool           // Axis helper visible flag
-	viewGrid         bool           // Grid helper visible flag
 	camPos           math32.Vector3 // Initial camera position
 	models           []*core.Node   // Models being shown
+	modelPaths       []string       // File of each model in models
+	watcher          *fileWatcher   // Reloads loaded files changed on disk
 	scene            *core.Node
 	cam              *camera.Camera
 	fpsCam           *camera.Camera
+	fly              *camera.FlyControl // Fly control for fpsCam
+	isFpsCamera      bool               // Fly camera active flag
 	focusMenu        *gui.Menu
 	focusModels      []*focusEntry
+	focusName        string // Name of the focused model
 	orbit            *camera.OrbitControl
+	archivePath      string           // Path of the opened archive
+	bookmarks        *bookmark.Store  // Camera bookmarks of the opened archive
+	bookmarkMenu     *gui.Menu        // Bookmarks menu
+	bookmarkEntries  []*bookmarkEntry // Bookmark menu slots
+	isZone           bool             // Zone archive flag
+	sceneWidth       float32          // Widest model size, scales point lights
+	lp               *LightingPanel   // Lighting editor
+	rigDialog        *NameDialog      // Lighting preset name dialog
+	rig              *lighting.Rig    // Active lighting rig
+	rigLights        []core.INode     // Lights created from rig
+	archiveNodes     []core.INode     // Nodes added for the opened archive
+	anims            []*animation.Animation
+	animSources      []*animSource   // Archives lending animations to the opened archive
+	animName         string          // Name of the playing animation
+	animMenu         *gui.Menu       // Animation menu
+	animItems        []*gui.MenuItem // Animation menu entries
+	recentMenu       *gui.Menu       // File > Recent submenu
+	recentItems      []*gui.MenuItem // Recent menu slots
+	flyItem          *gui.MenuItem   // View > fly camera checkbox
+	orthoItem        *gui.MenuItem   // View > orthographic projection checkbox
+	ab               *AssetBrowser   // EQ directory asset browser
+	indexer          *assetIndexer   // Background asset indexing
+	cp               *ComparePanel   // Archive comparison summary
+	compareNodes     []core.INode    // Models of the compared archive
+	compareLoader    *archiveLoader  // Builds and evicts the models of the compared archive
+	isComparing      bool            // File dialog picks the archive to compare
+	isAnimSource     bool            // File dialog picks an archive to borrow animations from
+	ip               *InfoPanel      // Focused model statistics
+	archiveQuail     *quail.Quail    // Contents of the opened archive
+	archiveModels    map[string]*common.Model
+	archiveWeights   map[string]skin.Weights // Bone weights of the archive models, by lower case name
+	mp               *MaterialPanel          // Material inspector
+	highlight        *graphic.Mesh           // Triangles marked over a model
+	pickInfo         *InfoPanel              // Details of the picked triangle
+	sp               *SkeletonPanel          // Bone weight viewer
+	skel             *skeletonOverlay        // Skeleton drawn over the focused model
+	boneLabel        *gui.Label              // Name of the joint under the cursor
+	bgScene          *core.Node              // Gradient or sky drawn behind the scene
+	skyLoad          int                     // Latest sky load, earlier results are dropped
+	skyResult        chan skyResult          // Skies prepared by loadSky
+	settings         *settings.Settings      // User settings persisted between sessions
+	loader           *archiveLoader          // Builds and evicts the models of the opened archive
+	loadBar          *LoadPanel              // Model loading progress
 }
 
 type focusEntry struct {
-	node      *core.Node
+	index     int        // Archive model index
+	node      *core.Node // Scene node of the model, nil until it's built
 	meshWidth float64
 	mi        *gui.MenuItem
 }
 
 func (e *focusEntry) onClick(evname string, ev interface{}) {
 	if e.node == nil {
+		if gv.loader != nil && e.index < len(gv.loader.models) {
+			// the model is built first, then focused
+			gv.whenModelShown(gv.loader.models[e.index].model.Header.Name, func() {
+				if e.node != nil {
+					e.onClick(evname, ev)
+				}
+			})
+			return
+		}
 		fmt.Println("Node not found for focus entry")
 		return
 	}
@@ -82,6 +142,18 @@ func (e *focusEntry) onClick(evname string, ev interface{}) {
 	gv.cam.LookAt(&pos, &math32.Vector3{X: 0, Y: 1, Z: 0})
 	gv.cam.SetPositionVec(&pos)
 	gv.orbit.Reset()
+	if gv.isFpsCamera {
+		target := e.node.Position()
+		gv.fly.Reposition(&pos)
+		gv.fly.Reorient(&target, &math32.Vector3{X: 0, Y: 1, Z: 0})
+		gv.fpsCam.LookAt(&target, &math32.Vector3{X: 0, Y: 1, Z: 0})
+	}
+
+	gv.focusName = e.node.Name()
+	gv.refreshBookmarkMenu()
+	gv.refreshInfo()
+	gv.refreshMaterialPanel()
+	gv.refreshSkeleton()
 
 	fmt.Println("Focusing on", e.node.Name())
 }
@@ -96,25 +168,30 @@ func main() {
 
 func run() error {
 
-	if len(os.Args) < 2 {
-		return fmt.Errorf("no file specified")
-	}
-
 	quail.SetLogLevel(2)
-	path := os.Args[1]
+	path := ""
+	if len(os.Args) > 1 {
+		path = os.Args[1]
+	}
 
 	gv = &g3nView{}
+	gv.watcher = newFileWatcher()
+
+	var err error
+	gv.settings, err = settings.Load()
+	if err != nil {
+		fmt.Println("Failed to load settings, using defaults:", err)
+	}
 
 	// Create application and scene
-	a := app.App(600, 600, fmt.Sprintf("quail-view v%s - %s", Version, filepath.Base(path)))
+	a := app.App(gv.settings.WindowWidth, gv.settings.WindowHeight, fmt.Sprintf("quail-view v%s", Version))
 	gv.Application = a
 
 	scene := core.NewNode()
 	gv.scene = scene
 
 	gv.axes = helper.NewAxes(2)
-	gv.viewAxes = true
-	gv.axes.SetVisible(gv.viewAxes)
+	gv.axes.SetVisible(gv.settings.ViewAxes)
 	gv.scene.Add(gv.axes)
 
 	// Set the scene to be managed by the gui manager
@@ -122,8 +199,7 @@ func run() error {
 
 	// Adds a grid helper to the scene initially not visible
 	gv.grid = helper.NewGrid(50, 1, &math32.Color{R: 0.4, G: 0.4, B: 0.4})
-	gv.viewGrid = true
-	gv.grid.SetVisible(gv.viewGrid)
+	gv.grid.SetVisible(gv.settings.ViewGrid)
 	gv.scene.Add(gv.grid)
 
 	gv.camPos = math32.Vector3{X: 8.3, Y: 4.7, Z: 3.7}
@@ -140,8 +216,8 @@ func run() error {
 	gv.fpsCam.SetVisible(false)
 	scene.Add(gv.fpsCam)
 
-	// Set up orbit control for the camera
-	camera.NewFlyControl(gv.fpsCam, &math32.Vector3{X: 0, Y: -10, Z: 0}, &math32.Vector3{X: 0, Y: 0, Z: 1}, camera.FlightSimStyle())
+	// Set up fly control for the fps camera
+	gv.newFlyControl()
 
 	// Set up callback to update viewport and camera aspect ratio when the window is resized
 	onResize := func(evname string, ev interface{}) {
@@ -150,103 +226,47 @@ func run() error {
 		a.Gls().Viewport(0, 0, int32(width), int32(height))
 		// Update the camera's aspect ratio
 		gv.cam.SetAspect(float32(width) / float32(height))
+		gv.fpsCam.SetAspect(float32(width) / float32(height))
+		gv.settings.WindowWidth, gv.settings.WindowHeight = width, height
 	}
 	a.Subscribe(window.OnWindowSize, onResize)
 	onResize("", nil)
 
 	gv.buildGui()
+	gv.setupDrop()
+	gv.setupPicking()
 
-	var err error
-
-	q := &quail.Quail{}
-	err = q.PfsRead(path)
+	// Create and add lights to the scene, zone point lights come from the zone light list
+	gv.sceneWidth = 3
+	rig := lighting.Presets()[0]
+	err = gv.applyRig(rig)
 	if err != nil {
-		return fmt.Errorf("pfs read: %w", err)
+		return fmt.Errorf("apply lighting: %w", err)
 	}
+	gv.lp.SetRig(rig)
+	gv.setFpsCamera(gv.settings.FlyCamera)
 
-	maxWidth := 3.0
-	riggedMeshes := make([]*graphic.RiggedMesh, 0)
-
-	for i := 0; i < len(q.Models); i++ {
-		var meshInstance core.INode
-		model := q.Models[i]
-		mesh, err := mesh.Generate(q, model)
+	// without an argument the previous session is reopened, or an empty viewer is shown
+	switch {
+	case path != "":
+		err = gv.openModel(path)
 		if err != nil {
-			return fmt.Errorf("generate: %w", err)
-		}
-
-		mesh.SetPosition(0, 0, float32(float64(i)*2.0))
-
-		meshInstance = mesh
-
-		if len(model.Bones) > 0 {
-			skel, err := skeleton.Generate(q.Models[i].Bones)
-			if err != nil {
-				return fmt.Errorf("generate skeleton: %w", err)
-			}
-
-			rigMesh := graphic.NewRiggedMesh(mesh)
-			rigMesh.SetSkeleton(skel)
-			meshInstance = rigMesh
-			riggedMeshes = append(riggedMeshes, rigMesh)
-		}
-
-		meshWidth := float64(mesh.BoundingBox().Max.X) * 2
-		if float64(mesh.BoundingBox().Max.Y)*2 > meshWidth {
-			meshWidth = float64(mesh.BoundingBox().Max.Y) * 2
-		}
-		if float64(mesh.BoundingBox().Max.Z)*2 > meshWidth {
-			meshWidth = float64(mesh.BoundingBox().Max.Z) * 2
-		}
-
-		if meshWidth > maxWidth {
-			maxWidth = meshWidth
+			return err
 		}
-
-		node := scene.Add(meshInstance)
-		node.SetName(model.Header.Name)
-
-		if len(gv.focusModels) > i {
-			fm := gv.focusModels[i]
-			fm.meshWidth = meshWidth
-			fm.node = node
-			fm.mi.SetVisible(true)
-			fm.mi.SetText(model.Header.Name)
+	case gv.settings.Session != nil:
+		err = gv.restoreSession()
+		if err != nil {
+			fmt.Println("Failed to restore session:", err)
+			gv.fs.Show(true)
 		}
+	default:
+		gv.fs.Show(true)
 	}
 
-	fmt.Println("total rigged meshes:", len(riggedMeshes))
-	anims, err := anim.Generate(q.Animations, riggedMeshes)
-	if err != nil {
-		return fmt.Errorf("generate anim: %w", err)
-	}
-
-	// Create and add lights to the scene
-	scene.Add(light.NewAmbient(&math32.Color{R: 1.0, G: 1.0, B: 1.0}, 2)) //0.8
-
-	base := float32(5.0)
-
-	pointLight := light.NewPoint(&math32.Color{R: 1, G: 1, B: 1}, base*float32(maxWidth)*0.5)
-	pointLight.SetPosition(1, 0, float32(maxWidth/2))
-	scene.Add(pointLight)
-	gv.cam.SetPosition(0, 0, float32(maxWidth))
-
-	pointLight = light.NewPoint(&math32.Color{R: 1, G: 1, B: 1}, base*float32(maxWidth)*0.5)
-	pointLight.SetPosition(float32(maxWidth/2), 0, 0)
-	scene.Add(pointLight)
-
-	pointLight = light.NewPoint(&math32.Color{R: 1, G: 1, B: 1}, base*float32(maxWidth)*0.5)
-	pointLight.SetPosition(0, float32(maxWidth/2), 0)
-	scene.Add(pointLight)
-
-	dir1 := light.NewDirectional(&math32.Color{R: 1, G: 1, B: 1}, 1.0)
-	dir1.SetPosition(0, 5, 10)
-	scene.Add(dir1)
-
 	// Create and add an axis helper to the scene
 	//scene.Add(helper.NewAxes(0.5))
 
-	a.Gls().ClearColor(0.2, 0.2, 0.2, 1)
+	gv.loadBackground()
 
 	/*
 		panel := gui.NewPanel(150, 30)
@@ -273,19 +293,37 @@ func run() error {
 		panel.Add(mb)
 	*/
 
-	//a.IWindow.(*window.GlfwWindow).SetTitle(fmt.Sprintf("quail-view v%s - %s", Version, filepath.Base(path)))
-
 	// Run the application
 	a.Run(func(renderer *renderer.Renderer, deltaTime time.Duration) {
 		a.Gls().Clear(gls.DEPTH_BUFFER_BIT | gls.STENCIL_BUFFER_BIT | gls.COLOR_BUFFER_BIT)
-		renderer.Render(scene, gv.cam)
-		if len(anims) > 0 {
-			anims[0].Update(float32(deltaTime.Seconds()))
-		}
+		gv.renderBackground(func(bgScene core.INode) error {
+			return renderer.Render(bgScene, gv.activeCam())
+		})
+		a.Gls().Clear(gls.DEPTH_BUFFER_BIT)
+		renderer.Render(scene, gv.activeCam())
+		gv.updateAnimations(float32(deltaTime.Seconds()))
+		gv.updateSkeleton()
+		gv.updateArchiveLoad()
+		gv.updateCompareLoad()
+		gv.updateBackground()
+		gv.updateAssetIndex()
+		gv.updateHotReload()
 	})
+
+	// window size is only kept in memory while resizing
+	gv.saveSession()
+	gv.saveSettings()
 	return nil
 }
 
+// saveSettings writes user settings, reporting failures to the console
+func (gv *g3nView) saveSettings() {
+	err := gv.settings.Save()
+	if err != nil {
+		fmt.Println("Failed to save settings:", err)
+	}
+}
+
 // setupGui builds the GUI
 func (gv *g3nView) buildGui() error {
 
@@ -299,8 +337,29 @@ func (gv *g3nView) buildGui() error {
 	// Create "File" menu and adds it to the menu bar
 	m1 := gui.NewMenu()
 	m1.AddOption("Open model").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.isComparing = false
+		gv.isAnimSource = false
+		gv.fs.Show(true)
+	})
+	m1.AddOption("Compare with archive").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		if gv.archivePath == "" {
+			gv.ed.Show("Open an archive before comparing")
+			return
+		}
+		gv.isComparing = true
+		gv.isAnimSource = false
 		gv.fs.Show(true)
 	})
+	m1.AddOption("Import model into archive").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		err := gv.importModel()
+		if err != nil {
+			gv.ed.Show(err.Error())
+		}
+	})
+	gv.buildRecentMenu(m1)
+	m1.AddOption("Browse EQ assets").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.showAssetBrowser()
+	})
 	m1.AddOption("Remove models").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
 		gv.removeModels()
 	})
@@ -318,19 +377,70 @@ func (gv *g3nView) buildGui() error {
 	// Create "View" menu and adds it to the menu bar
 	m2 := gui.NewMenu()
 	vAxis := m2.AddOption("View axis helper").SetIcon(checkOFF)
-	vAxis.SetIcon(getIcon(gv.viewAxes))
+	vAxis.SetIcon(getIcon(gv.settings.ViewAxes))
 	vAxis.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
-		gv.viewAxes = !gv.viewAxes
-		vAxis.SetIcon(getIcon(gv.viewAxes))
-		gv.axes.SetVisible(gv.viewAxes)
+		gv.settings.ViewAxes = !gv.settings.ViewAxes
+		vAxis.SetIcon(getIcon(gv.settings.ViewAxes))
+		gv.axes.SetVisible(gv.settings.ViewAxes)
+		gv.saveSettings()
 	})
 
 	vGrid := m2.AddOption("View grid helper").SetIcon(checkOFF)
-	vGrid.SetIcon(getIcon(gv.viewGrid))
+	vGrid.SetIcon(getIcon(gv.settings.ViewGrid))
 	vGrid.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
-		gv.viewGrid = !gv.viewGrid
-		vGrid.SetIcon(getIcon(gv.viewGrid))
-		gv.grid.SetVisible(gv.viewGrid)
+		gv.settings.ViewGrid = !gv.settings.ViewGrid
+		vGrid.SetIcon(getIcon(gv.settings.ViewGrid))
+		gv.grid.SetVisible(gv.settings.ViewGrid)
+		gv.saveSettings()
+	})
+
+	vInfo := m2.AddOption("View model info")
+	vInfo.SetIcon(getIcon(gv.settings.ViewInfo))
+	vInfo.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.settings.ViewInfo = !gv.settings.ViewInfo
+		vInfo.SetIcon(getIcon(gv.settings.ViewInfo))
+		gv.ip.SetVisible(gv.settings.ViewInfo)
+		gv.saveSettings()
+	})
+
+	m2.AddSeparator()
+	gv.buildViewPresetMenu(m2)
+
+	m2.AddSeparator()
+	gv.flyItem = m2.AddOption("Toggle View Mode").SetIcon(checkOFF)
+	gv.flyItem.SetIcon(getIcon(gv.settings.FlyCamera))
+	gv.flyItem.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.setFlyCamera(!gv.isFpsCamera)
+	})
+	m2.AddOption("Increase fly speed").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.setFlySpeed(gv.settings.FlySpeed * 2)
+		gv.saveSettings()
+	})
+	m2.AddOption("Decrease fly speed").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.setFlySpeed(gv.settings.FlySpeed / 2)
+		gv.saveSettings()
+	})
+
+	m2.AddSeparator()
+	vReload := m2.AddOption("Reload changed files")
+	vReload.SetIcon(getIcon(gv.settings.HotReload))
+	vReload.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.settings.HotReload = !gv.settings.HotReload
+		vReload.SetIcon(getIcon(gv.settings.HotReload))
+		gv.saveSettings()
+	})
+
+	m2.AddSeparator()
+	gv.buildBackgroundMenu(m2)
+	m2.AddOption("Lighting").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.lp.Show(!gv.lp.Visible())
+	})
+	m2.AddOption("Material inspector").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.mp.Show(!gv.mp.Visible())
+	})
+	m2.AddOption("Skeleton").Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.sp.Show(!gv.sp.Visible())
+		gv.refreshSkeleton()
 	})
 
 	mb.AddMenu("View", m2)
@@ -345,28 +455,12 @@ func (gv *g3nView) buildGui() error {
 		gv.focusModels = append(gv.focusModels, fe)
 	}
 	mb.AddMenu("Center On", gv.focusMenu)
-	// vView := m2.AddOption("Toggle View Mode").SetIcon(checkOFF)
-	// vView.SetIcon(getIcon(gv.isFpsCamera))
-	// vView.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
-	// 	gv.isFpsCamera = !gv.isFpsCamera
-	// 	vView.SetIcon(getIcon(gv.isFpsCamera))
-	// 	if gv.isFpsCamera {
-	// 		pos := gv.cam.Position()
-	// 		gv.fpsCam.SetPositionVec(&pos)
-	// 		rot := gv.cam.Rotation()
-	// 		gv.fpsCam.SetRotationVec(&rot)
-	// 	} else {
-	// 		pos := gv.fpsCam.Position()
-	// 		gv.cam.SetPositionVec(&pos)
-	// 		rot := gv.fpsCam.Rotation()
-	// 		gv.cam.SetRotationVec(&rot)
-	// 	}
-	// 	gv.cam.SetVisible(!gv.isFpsCamera)
-	// 	gv.fpsCam.SetVisible(gv.isFpsCamera)
-	// })
+
+	gv.buildAnimMenu(mb)
+	gv.buildBookmarkMenu(mb)
 
 	// Creates file selection dialog
-	fs, err := NewFileSelect(400, 300)
+	fs, err := NewFileSelect(400, 300, gv.settings.FileDir)
 	if err != nil {
 		return err
 	}
@@ -378,19 +472,43 @@ func (gv *g3nView) buildGui() error {
 			gv.ed.Show("File not selected")
 			return
 		}
-		err := gv.openModel(fpath)
+		open := gv.openModel
+		if gv.isComparing {
+			open = gv.openCompare
+		}
+		if gv.isAnimSource {
+			open = gv.addAnimSource
+		}
+		err := open(fpath)
 		if err != nil {
 			gv.ed.Show(err.Error())
 			return
 		}
+		gv.isComparing = false
+		gv.isAnimSource = false
 		gv.fs.SetVisible(false)
+		gv.settings.FileDir = filepath.Dir(fpath)
+		gv.saveSettings()
 
 	})
 	gv.fs.Subscribe("OnCancel", func(evname string, ev interface{}) {
+		gv.isComparing = false
+		gv.isAnimSource = false
 		gv.fs.Show(false)
 	})
+	gv.fs.Subscribe("OnError", func(evname string, ev interface{}) {
+		gv.ed.Show(ev.(error).Error())
+	})
 	gv.scene.Add(gv.fs)
 
+	gv.buildLightingPanel()
+	gv.buildAssetBrowser()
+	gv.buildComparePanel()
+	gv.buildInfoPanel()
+	gv.buildMaterialPanel()
+	gv.buildSkeletonPanel()
+	gv.buildLoadPanel()
+
 	// Creates error dialog
 	gv.ed = NewErrorDialog(600, 100)
 	gv.scene.Add(gv.ed)
@@ -402,7 +520,12 @@ func (gv *g3nView) buildGui() error {
 func (gv *g3nView) openModel(fpath string) error {
 
 	dir, file := filepath.Split(fpath)
-	ext := filepath.Ext(file)
+	ext := strings.ToLower(filepath.Ext(file))
+
+	// Loads EQ archive, replacing the one opened
+	if ext == ".eqg" || ext == ".s3d" {
+		return gv.openArchive(fpath)
+	}
 
 	// Loads OBJ model
 	if ext == ".obj" {
@@ -427,6 +550,11 @@ func (gv *g3nView) openModel(fpath string) error {
 		}
 		gv.scene.Add(group)
 		gv.models = append(gv.models, group)
+		gv.modelPaths = append(gv.modelPaths, fpath)
+		gv.watcher.add(fpath)
+		gv.settings.AddRecent(fpath)
+		gv.refreshRecentMenu()
+		gv.saveSettings()
 		return nil
 	}
 
@@ -445,6 +573,40 @@ func (gv *g3nView) openModel(fpath string) error {
 		}
 		gv.scene.Add(s)
 		gv.models = append(gv.models, s.GetNode())
+		gv.modelPaths = append(gv.modelPaths, fpath)
+		gv.watcher.add(fpath)
+		gv.settings.AddRecent(fpath)
+		gv.refreshRecentMenu()
+		gv.saveSettings()
+		return nil
+	}
+	// Loads glTF model
+	if ext == ".gltf" || ext == ".glb" {
+		var g *gltf.GLTF
+		var err error
+		if ext == ".glb" {
+			g, err = gltf.ParseBin(fpath)
+		} else {
+			g, err = gltf.ParseJSON(fpath)
+		}
+		if err != nil {
+			return err
+		}
+		sceneIdx := 0
+		if g.Scene != nil {
+			sceneIdx = *g.Scene
+		}
+		s, err := g.LoadScene(sceneIdx)
+		if err != nil {
+			return err
+		}
+		gv.scene.Add(s)
+		gv.models = append(gv.models, s.GetNode())
+		gv.modelPaths = append(gv.modelPaths, fpath)
+		gv.watcher.add(fpath)
+		gv.settings.AddRecent(fpath)
+		gv.refreshRecentMenu()
+		gv.saveSettings()
 		return nil
 	}
 	return fmt.Errorf("Unrecognized model file extension:[%s]", ext)
@@ -457,7 +619,11 @@ func (gv *g3nView) removeModels() {
 		gv.scene.Remove(model)
 		model.Dispose()
 	}
+	for _, path := range gv.modelPaths {
+		gv.watcher.remove(path)
+	}
 	gv.models = nil
+	gv.modelPaths = nil
 }
 
 func getIcon(state bool) string {
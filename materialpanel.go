@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/xackery/engine/app"
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/mesh"
+	"github.com/xackery/quail/common"
+)
+
+// texturePreviewSize is the width and height texture previews are drawn at
+const texturePreviewSize = 96
+
+type MaterialPanel struct {
+	gui.Panel
+	materials  []*common.Material
+	ddMaterial *gui.DropDown
+	props      *gui.List
+	previews   *gui.Panel
+	bclose     *gui.Button
+	updating   bool
+}
+
+func NewMaterialPanel(width, height float32) *MaterialPanel {
+
+	mp := new(MaterialPanel)
+	mp.Panel.Initialize(mp, width, height)
+	mp.SetBorders(2, 2, 2, 2)
+	mp.SetPaddings(4, 4, 4, 4)
+	mp.SetColor(math32.NewColor("White"))
+	mp.SetVisible(false)
+	mp.SetBounded(false)
+
+	// Set vertical box layout for the whole panel
+	l := gui.NewVBoxLayout()
+	l.SetSpacing(4)
+	mp.SetLayout(l)
+
+	mp.Add(gui.NewLabel("Material"))
+	mp.ddMaterial = gui.NewDropDown(width-16, gui.NewImageLabel(""))
+	mp.ddMaterial.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		if mp.updating {
+			return
+		}
+		mp.Dispatch("OnMaterial", mp.Selected())
+	})
+	mp.Add(mp.ddMaterial)
+
+	mp.props = gui.NewVList(0, 0)
+	mp.props.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 3, AlignH: gui.AlignWidth})
+	mp.Add(mp.props)
+
+	// Texture previews are laid out in a row
+	mp.previews = gui.NewPanel(0, texturePreviewSize)
+	pl := gui.NewHBoxLayout()
+	pl.SetSpacing(4)
+	mp.previews.SetLayout(pl)
+	mp.previews.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 0, AlignH: gui.AlignWidth})
+	mp.Add(mp.previews)
+
+	// Creates Close button
+	mp.bclose = gui.NewButton("Close")
+	mp.bclose.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 0, AlignH: gui.AlignCenter})
+	mp.bclose.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		mp.Dispatch("OnClose", nil)
+	})
+	mp.Add(mp.bclose)
+
+	return mp
+}
+
+// Show shows or hide the material inspector
+func (mp *MaterialPanel) Show(show bool) {
+
+	if !show {
+		mp.SetVisible(false)
+		return
+	}
+	mp.SetVisible(true)
+	width, height := app.App(300, 300, "Materials").GetSize()
+	mp.SetPosition(float32(width)-mp.Width()-10, float32(height)-mp.Height()-10)
+}
+
+// SetMaterials lists materials in the material drop down
+func (mp *MaterialPanel) SetMaterials(materials []*common.Material) {
+	mp.updating = true
+	mp.materials = materials
+	for mp.ddMaterial.Len() > 0 {
+		mp.ddMaterial.RemoveAt(0)
+	}
+	for _, mat := range materials {
+		mp.ddMaterial.Add(gui.NewImageLabel(mat.Name))
+	}
+	mp.updating = false
+	mp.props.Clear()
+	mp.previews.DisposeChildren(true)
+}
+
+// Selected returns the material picked in the drop down
+func (mp *MaterialPanel) Selected() *common.Material {
+	pos := mp.ddMaterial.SelectedPos()
+	if pos < 0 || pos >= len(mp.materials) {
+		return nil
+	}
+	return mp.materials[pos]
+}
+
+// SetProperties shows the properties of mat and previews of its textures
+func (mp *MaterialPanel) SetProperties(mat *common.Material, previews []*gui.Image) {
+	mp.props.Clear()
+	mp.previews.DisposeChildren(true)
+	if mat == nil {
+		return
+	}
+
+	mp.props.Add(gui.NewLabel(fmt.Sprintf("Shader: %s, flag: %d", mat.ShaderName, mat.Flag)))
+	for _, property := range mat.Properties {
+		line := fmt.Sprintf("%s [%d] = %s", property.Name, property.Category, property.Value)
+		if len(property.Data) > 0 {
+			line += fmt.Sprintf(" (%d bytes embedded)", len(property.Data))
+		}
+		mp.props.Add(gui.NewLabel(line))
+	}
+	if len(mat.Animation.Textures) > 0 {
+		mp.props.Add(gui.NewLabel(fmt.Sprintf("Animated, %d textures, %dms", len(mat.Animation.Textures), mat.Animation.Sleep)))
+	}
+	for _, img := range previews {
+		img.SetContentSize(texturePreviewSize, texturePreviewSize)
+		mp.previews.Add(img)
+	}
+}
+
+// buildMaterialPanel creates the material inspector
+func (gv *g3nView) buildMaterialPanel() {
+	gv.mp = NewMaterialPanel(360, 400)
+	gv.mp.Subscribe("OnMaterial", func(evname string, ev interface{}) {
+		gv.inspectMaterial(ev.(*common.Material))
+	})
+	gv.mp.Subscribe("OnClose", func(evname string, ev interface{}) {
+		gv.clearHighlight()
+		gv.mp.Show(false)
+	})
+	gv.scene.Add(gv.mp)
+}
+
+// refreshMaterialPanel lists the materials of the focused model
+func (gv *g3nView) refreshMaterialPanel() {
+	gv.clearHighlight()
+	model := gv.archiveModels[gv.focusName]
+	if model == nil {
+		gv.mp.SetMaterials(nil)
+		return
+	}
+	gv.mp.SetMaterials(model.Materials)
+}
+
+// inspectMaterial shows the properties of mat and highlights the triangles using it
+func (gv *g3nView) inspectMaterial(mat *common.Material) {
+	gv.clearHighlight()
+	model := gv.archiveModels[gv.focusName]
+	if mat == nil || model == nil {
+		gv.mp.SetProperties(nil, nil)
+		return
+	}
+
+	previews := []*gui.Image{}
+	for _, property := range mat.Properties {
+		if !mesh.IsTextureProperty(property) {
+			continue
+		}
+		img, err := mesh.TextureImage(gv.archiveQuail, property)
+		if err != nil {
+			fmt.Println("Failed to preview", property.Value, err)
+			continue
+		}
+		previews = append(previews, gui.NewImageFromRGBA(img))
+	}
+	gv.mp.SetProperties(mat, previews)
+
+	triangles := mesh.TrianglesWithMaterial(model, mat.Name)
+	fmt.Printf("Material %s is used by %d triangles\n", mat.Name, len(triangles))
+	gv.setHighlight(gv.focusedNode(), mesh.Highlight(model, triangles, math32.NewColor("Yellow")))
+}
+
+// focusedNode returns the scene node of the focused model
+func (gv *g3nView) focusedNode() *core.Node {
+	for _, fm := range gv.focusModels {
+		if fm.node != nil && fm.node.Name() == gv.focusName {
+			return fm.node
+		}
+	}
+	return nil
+}
+
+// setHighlight shows highlight over the model node, replacing any previous one
+func (gv *g3nView) setHighlight(node *core.Node, highlight *graphic.Mesh) {
+	gv.clearHighlight()
+	if node == nil {
+		highlight.Dispose()
+		return
+	}
+	node.Add(highlight)
+	gv.highlight = highlight
+}
+
+// clearHighlight removes the highlight from the scene
+func (gv *g3nView) clearHighlight() {
+	if gv.highlight == nil {
+		return
+	}
+	if parent := gv.highlight.Parent(); parent != nil {
+		parent.GetNode().Remove(gv.highlight)
+	}
+	gv.highlight.Dispose()
+	gv.highlight = nil
+}
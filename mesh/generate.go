@@ -8,6 +8,7 @@ import (
 	"image/png"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/malashin/dds"
 	"github.com/sergeymakinen/go-bmp"
@@ -25,87 +26,152 @@ import (
 )
 
 var (
-	fallbackImg *image.RGBA
+	fallbackImg  *image.RGBA
+	fallbackOnce sync.Once
 )
 
+// Prepared is the CPU side of a model mesh: decoded textures and vertex arrays ready
+// for Build to upload. Preparing is safe to run on several goroutines at once
+type Prepared struct {
+	Model     *common.Model
+	Bounds    math32.Box3
+	materials []*preparedMaterial
+	positions math32.ArrayF32
+	normals   math32.ArrayF32
+	uvs       math32.ArrayF32
+	indices   math32.ArrayU32
+	groups    []preparedGroup
+	skinIndex math32.ArrayF32
+	skinValue math32.ArrayF32
+}
+
+type preparedMaterial struct {
+	name   string
+	images []*image.RGBA
+}
+
+type preparedGroup struct {
+	start    int
+	count    int
+	material int
+}
+
+// Generate returns the mesh of in, preparing and building it on the calling goroutine
 func Generate(q *quail.Quail, in *common.Model) (*graphic.Mesh, error) {
-	mats := make(map[string]*material.Standard)
+	p, err := Prepare(q, in)
+	if err != nil {
+		return nil, err
+	}
+	return p.Build(), nil
+}
+
+// Prepare decodes the textures of in and builds its vertex arrays and bounds
+func Prepare(q *quail.Quail, in *common.Model) (*Prepared, error) {
+	p := &Prepared{Model: in}
 	matIndexes := make(map[string]int)
 
 	for _, mat := range in.Materials {
-		newMat, ok := mats[mat.Name]
+		index, ok := matIndexes[mat.Name]
 		if !ok {
-			newMat = material.NewStandard(math32.NewColor("gray"))
 			//newMat.SetShader("MaxCB1")
 			//newMat.SetShader("MPLBasic")
-			matIndexes[mat.Name] = len(mats)
-			mats[mat.Name] = newMat
+			index = len(p.materials)
+			matIndexes[mat.Name] = index
+			p.materials = append(p.materials, &preparedMaterial{name: mat.Name})
 		}
+		newMat := p.materials[index]
 
 		for _, property := range mat.Properties {
-			if property.Category != 2 {
+			if !IsTextureProperty(property) {
 				continue
 			}
 
-			if !strings.Contains(strings.ToLower(property.Name), "texture") {
-				continue
-			}
-			data := property.Data
-			if len(data) == 0 {
-				for name, textureData := range q.Textures {
-					if strings.EqualFold(name, property.Value) {
-						data = textureData
-						break
-					}
-				}
-			}
-
-			img, err := generateImage(property.Value, data)
+			img, err := generateImage(property.Value, textureData(q, property))
 			if err != nil {
 				return nil, fmt.Errorf("generate image: %w", err)
 			}
 
-			newMat.AddTexture(texture.NewTexture2DFromRGBA(img))
+			newMat.images = append(newMat.images, img)
 		}
 	}
 
-	geom := geometry.NewGeometry()
-
-	positions := math32.NewArrayF32(0, 16)
-	normals := math32.NewArrayF32(0, 16)
-	uvs := math32.NewArrayF32(0, 16)
-	indices := math32.NewArrayU32(0, 16)
+	p.positions = math32.NewArrayF32(0, len(in.Vertices)*3)
+	p.normals = math32.NewArrayF32(0, len(in.Vertices)*3)
+	p.uvs = math32.NewArrayF32(0, len(in.Vertices)*2)
+	p.indices = math32.NewArrayU32(0, len(in.Triangles)*3)
+	p.Bounds = Bounds(in)
 
 	for i := 0; i < len(in.Vertices); i++ {
-		positions.Append(float32(in.Vertices[i].Position.X), float32(in.Vertices[i].Position.Y), float32(in.Vertices[i].Position.Z))
-		normals.Append(float32(in.Vertices[i].Normal.X), float32(in.Vertices[i].Normal.Y), float32(in.Vertices[i].Normal.Z))
-		uvs.Append(float32(in.Vertices[i].Uv.X), float32(in.Vertices[i].Uv.Y))
+		p.positions.Append(in.Vertices[i].Position.X, in.Vertices[i].Position.Y, in.Vertices[i].Position.Z)
+		p.normals.Append(float32(in.Vertices[i].Normal.X), float32(in.Vertices[i].Normal.Y), float32(in.Vertices[i].Normal.Z))
+		p.uvs.Append(float32(in.Vertices[i].Uv.X), float32(in.Vertices[i].Uv.Y))
 	}
 
 	lastMat := ""
 	lastIndex := 0
 	for i := 0; i < len(in.Triangles); i++ {
-		indices.Append(uint32(in.Triangles[i].Index.X), uint32(in.Triangles[i].Index.Y), uint32(in.Triangles[i].Index.Z))
-		geom.AddGroup(lastIndex, i-lastIndex, matIndexes[lastMat])
+		p.indices.Append(uint32(in.Triangles[i].Index.X), uint32(in.Triangles[i].Index.Y), uint32(in.Triangles[i].Index.Z))
+		p.groups = append(p.groups, preparedGroup{start: lastIndex, count: i - lastIndex, material: matIndexes[lastMat]})
 		lastMat = in.Triangles[i].MaterialName
 		lastIndex = i
 	}
+	return p, nil
+}
 
-	geom.SetIndices(indices)
-	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
-	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
-	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+// Bounds returns the box around the vertices of in, without generating its mesh
+func Bounds(in *common.Model) math32.Box3 {
+	var box math32.Box3
+	box.MakeEmpty()
+	for _, v := range in.Vertices {
+		box.ExpandByPoint(&math32.Vector3{X: v.Position.X, Y: v.Position.Y, Z: v.Position.Z})
+	}
+	return box
+}
+
+// Size estimates the GPU memory in bytes the mesh of p uses once built
+func (p *Prepared) Size() int {
+	size := 4 * (len(p.positions) + len(p.normals) + len(p.uvs) + len(p.indices) + len(p.skinIndex) + len(p.skinValue))
+	for _, pm := range p.materials {
+		for _, img := range pm.images {
+			size += len(img.Pix)
+		}
+	}
+	return size
+}
+
+// Build creates the mesh of p, which uploads to the GPU when first rendered
+func (p *Prepared) Build() *graphic.Mesh {
+	mats := make([]*material.Standard, len(p.materials))
+	for i, pm := range p.materials {
+		mats[i] = material.NewStandard(math32.NewColor("gray"))
+		for _, img := range pm.images {
+			mats[i].AddTexture(texture.NewTexture2DFromRGBA(img))
+		}
+	}
+
+	geom := geometry.NewGeometry()
+	for _, g := range p.groups {
+		geom.AddGroup(g.start, g.count, g.material)
+	}
+	geom.SetIndices(p.indices)
+	geom.AddVBO(gls.NewVBO(p.positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(p.normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(p.uvs).AddAttrib(gls.VertexTexcoord))
+	if p.skinIndex != nil {
+		geom.AddVBO(gls.NewVBO(p.skinIndex).AddAttrib(gls.SkinIndex))
+		geom.AddVBO(gls.NewVBO(p.skinValue).AddAttrib(gls.SkinWeight))
+	}
 
 	//mat := material.NewStandard(math32.NewColor("DarkBlue"))
 	mesh := graphic.NewMesh(geom, nil)
 
-	for name, idx := range matIndexes {
-		mesh.AddGroupMaterial(mats[name], idx)
+	for idx, mat := range mats {
+		mesh.AddGroupMaterial(mat, idx)
 	}
 
 	//fmt.Printf("%d total materials, %d triangles\n", len(matIndexes), len(in.Triangles))
 
-	return mesh, nil
+	return mesh
 }
 
 func generateImage(name string, data []byte) (*image.RGBA, error) {
@@ -182,19 +248,16 @@ func generateImage(name string, data []byte) (*image.RGBA, error) {
 	return nil, fmt.Errorf("unknown image type %s", name)
 }
 
+// fallback returns the pink image shown for textures that can't be decoded
 func fallback() *image.RGBA {
-	if fallbackImg != nil {
-		return fallbackImg
-	}
-	var img image.Image
-	img = image.NewRGBA(image.Rect(0, 0, 64, 64))
-	dimg := img.(draw.Image)
-
-	for x := 0; x < 64; x++ {
-		for y := 0; y < 64; y++ {
-			dimg.Set(x, y, colors.Magenta)
+	fallbackOnce.Do(func() {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+		for x := 0; x < 64; x++ {
+			for y := 0; y < 64; y++ {
+				img.Set(x, y, colors.Magenta)
+			}
 		}
-	}
-	fallbackImg = img.(*image.RGBA)
+		fallbackImg = img
+	})
 	return fallbackImg
 }
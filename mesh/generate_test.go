@@ -1,30 +1,114 @@
 package mesh
 
 import (
-	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/gls"
 	"github.com/xackery/engine/graphic"
+	"github.com/xackery/quail-view/internal/fixture"
 	"github.com/xackery/quail-view/skeleton"
+	"github.com/xackery/quail/common"
 	"github.com/xackery/quail/quail"
 )
 
 func TestMesh(t *testing.T) {
-	q := &quail.Quail{}
+	q := fixture.Archive()
+	for _, model := range q.Models {
+		mesh, err := Generate(q, model)
+		if err != nil {
+			t.Fatalf("generate %s: %s", model.Header.Name, err)
+		}
+		positions := mesh.GetGeometry().VBO(gls.VertexPosition).Buffer()
+		if positions.Size() != len(model.Vertices)*3 {
+			t.Fatalf("%s: %d position values, expected %d", model.Header.Name, positions.Size(), len(model.Vertices)*3)
+		}
+		indices := mesh.GetGeometry().Indices()
+		if len(indices) != len(model.Triangles)*3 {
+			t.Fatalf("%s: %d indices, expected %d", model.Header.Name, len(indices), len(model.Triangles)*3)
+		}
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	q := fixture.Archive()
+	prepared := make([]*Prepared, len(q.Models))
+	errs := make([]error, len(q.Models))
+	wg := sync.WaitGroup{}
+	for i, model := range q.Models {
+		wg.Add(1)
+		go func(i int, model *common.Model) {
+			defer wg.Done()
+			prepared[i], errs[i] = Prepare(q, model)
+			if errs[i] == nil && len(model.Bones) > 0 {
+				prepared[i].PrepareSkin(len(model.Bones), nil)
+			}
+		}(i, model)
+	}
+	wg.Wait()
+
+	for i, p := range prepared {
+		if errs[i] != nil {
+			t.Fatalf("prepare %s: %s", q.Models[i].Header.Name, errs[i])
+		}
+		if p.Bounds.Max.X != 0.5 || p.Bounds.Min.Y != -0.5 {
+			t.Fatalf("%s: bounds %v, expected a unit cube", q.Models[i].Header.Name, p.Bounds)
+		}
+		if p.Size() < len(q.Models[i].Vertices)*32 {
+			t.Fatalf("%s: size %d smaller than its vertices", q.Models[i].Header.Name, p.Size())
+		}
+		mesh := p.Build()
+		hasSkin := mesh.GetGeometry().VBO(gls.SkinIndex) != nil
+		if hasSkin != (len(q.Models[i].Bones) > 0) {
+			t.Fatalf("%s: skin %t, expected %t", q.Models[i].Header.Name, hasSkin, len(q.Models[i].Bones) > 0)
+		}
+		if !hasSkin {
+			continue
+		}
+		weights := mesh.GetGeometry().VBO(gls.SkinWeight).Buffer()
+		if weights.Size() != len(q.Models[i].Vertices)*graphic.MaxBoneInfluencers {
+			t.Fatalf("%s: %d weight values, expected %d", q.Models[i].Header.Name, weights.Size(), len(q.Models[i].Vertices)*graphic.MaxBoneInfluencers)
+		}
+		// vertices without weights follow the root bone fully
+		if (*weights)[0] != 1 {
+			t.Fatalf("%s: first weight %f, expected 1", q.Models[i].Header.Name, (*weights)[0])
+		}
+	}
+}
+
+func TestInfo(t *testing.T) {
+	q := fixture.Archive()
+	info := Info(q, q.Models[0])
+	if info.Size != [3]float32{1, 1, 1} {
+		t.Fatalf("size %v, expected a unit cube", info.Size)
+	}
+	if len(info.Materials) != len(fixture.Materials) {
+		t.Fatalf("%d materials, expected %d", len(info.Materials), len(fixture.Materials))
+	}
+	for _, mi := range info.Materials {
+		for _, ti := range mi.Textures {
+			if strings.Contains(ti.Format, "failed") || ti.Format == "missing" || ti.Width != 4 {
+				t.Fatalf("%s texture %s: format %s width %d", mi.Name, ti.Name, ti.Format, ti.Width)
+			}
+		}
+	}
+}
+
+// TestMeshArchive generates every model of a real archive, when EQ_PATH points at an install
+func TestMeshArchive(t *testing.T) {
 	path := os.Getenv("EQ_PATH")
 	if path == "" {
-		t.Fatalf("EQ_PATH not set")
+		t.Skip("EQ_PATH not set")
 	}
+	q := &quail.Quail{}
 	err := q.PfsRead(path + "/ael_chr.s3d")
 	if err != nil {
 		t.Fatalf("pfs read: %s", err.Error())
 	}
 
-	maxWidth := 3.0
-	riggedMeshes := make([]*graphic.RiggedMesh, 0)
-
 	for i := 0; i < len(q.Models); i++ {
 		var meshInstance core.INode
 		model := q.Models[i]
@@ -32,9 +116,6 @@ func TestMesh(t *testing.T) {
 		if err != nil {
 			t.Fatalf("generate: %s", err.Error())
 		}
-
-		mesh.SetPosition(0, 0, float32(float64(i)*2.0))
-
 		meshInstance = mesh
 
 		if len(model.Bones) > 0 {
@@ -46,21 +127,7 @@ func TestMesh(t *testing.T) {
 			rigMesh := graphic.NewRiggedMesh(mesh)
 			rigMesh.SetSkeleton(skel)
 			meshInstance = rigMesh
-			riggedMeshes = append(riggedMeshes, rigMesh)
-		}
-
-		meshWidth := float64(mesh.BoundingBox().Max.X) * 2
-		if float64(mesh.BoundingBox().Max.Y)*2 > meshWidth {
-			meshWidth = float64(mesh.BoundingBox().Max.Y) * 2
 		}
-		if float64(mesh.BoundingBox().Max.Z)*2 > meshWidth {
-			meshWidth = float64(mesh.BoundingBox().Max.Z) * 2
-		}
-
-		if meshWidth > maxWidth {
-			maxWidth = meshWidth
-		}
-
-		fmt.Println("done", meshInstance)
+		t.Log("generated", meshInstance.GetNode().Name(), model.Header.Name)
 	}
 }
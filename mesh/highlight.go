@@ -0,0 +1,85 @@
+package mesh
+
+import (
+	"github.com/xackery/engine/geometry"
+	"github.com/xackery/engine/gls"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/engine/material"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail/common"
+)
+
+// TrianglesWithMaterial returns the indexes of the triangles of in using the material named name
+func TrianglesWithMaterial(in *common.Model, name string) []int {
+	triangles := []int{}
+	for i, tri := range in.Triangles {
+		if tri.MaterialName == name {
+			triangles = append(triangles, i)
+		}
+	}
+	return triangles
+}
+
+// Highlight returns a mesh of the given triangles of in, drawn in color over the model.
+// It should be added as a child of the model mesh so it follows its transform
+func Highlight(in *common.Model, triangles []int, color *math32.Color) *graphic.Mesh {
+	geom := geometry.NewGeometry()
+
+	positions := math32.NewArrayF32(0, len(triangles)*9)
+	normals := math32.NewArrayF32(0, len(triangles)*9)
+	for _, i := range triangles {
+		if i < 0 || i >= len(in.Triangles) {
+			continue
+		}
+		idx := in.Triangles[i].Index
+		for _, vi := range []uint32{idx.X, idx.Y, idx.Z} {
+			if int(vi) >= len(in.Vertices) {
+				continue
+			}
+			v := in.Vertices[vi]
+			positions.Append(v.Position.X, v.Position.Y, v.Position.Z)
+			normals.Append(v.Normal.X, v.Normal.Y, v.Normal.Z)
+		}
+	}
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+
+	mat := material.NewStandard(color)
+	mat.SetEmissiveColor(color)
+	mat.SetOpacity(0.6)
+	mat.SetTransparent(true)
+	mat.SetSide(material.SideDouble)
+	// pull the highlight towards the camera so it wins the depth test against the model
+	mat.SetPolygonOffset(-1, -1)
+	return graphic.NewMesh(geom, mat)
+}
+
+// Heatmap returns a copy of the triangles of in with each vertex colored by weights,
+// blue for 0 through red for 1, drawn over the model like Highlight
+func Heatmap(in *common.Model, weights []float32) *graphic.Mesh {
+	geom := geometry.NewGeometry()
+
+	positions := math32.NewArrayF32(0, len(in.Triangles)*9)
+	colors := math32.NewArrayF32(0, len(in.Triangles)*9)
+	for _, tri := range in.Triangles {
+		for _, vi := range []uint32{tri.Index.X, tri.Index.Y, tri.Index.Z} {
+			if int(vi) >= len(in.Vertices) {
+				continue
+			}
+			v := in.Vertices[vi]
+			w := float32(0)
+			if int(vi) < len(weights) {
+				w = math32.Clamp(weights[vi], 0, 1)
+			}
+			positions.Append(v.Position.X, v.Position.Y, v.Position.Z)
+			colors.Append(w, 0, 1-w)
+		}
+	}
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+
+	mat := material.NewBasic()
+	mat.SetSide(material.SideDouble)
+	mat.SetPolygonOffset(-1, -1)
+	return graphic.NewMesh(geom, mat)
+}
@@ -0,0 +1,128 @@
+package mesh
+
+import (
+	"image"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail/common"
+	"github.com/xackery/quail/quail"
+)
+
+// ModelInfo is a summary of a model for display
+type ModelInfo struct {
+	Name      string
+	Vertices  int
+	Triangles int
+	Bones     int
+	Size      [3]float32 // Bounding box width, height and depth
+	Materials []*MaterialInfo
+}
+
+// MaterialInfo is a summary of a material and the textures it uses
+type MaterialInfo struct {
+	Name     string
+	Shader   string
+	Textures []*TextureInfo
+}
+
+// TextureInfo is a texture referenced by a material property
+type TextureInfo struct {
+	Name   string
+	Format string
+	Width  int
+	Height int
+	Size   int // Encoded size in bytes
+}
+
+// Info summarizes in, decoding its textures to report their dimensions
+func Info(q *quail.Quail, in *common.Model) *ModelInfo {
+	info := &ModelInfo{
+		Name:      in.Header.Name,
+		Vertices:  len(in.Vertices),
+		Triangles: len(in.Triangles),
+		Bones:     len(in.Bones),
+	}
+
+	if len(in.Vertices) > 0 {
+		min := in.Vertices[0].Position
+		max := in.Vertices[0].Position
+		for _, v := range in.Vertices {
+			p := v.Position
+			min.X, max.X = minMax(min.X, max.X, p.X)
+			min.Y, max.Y = minMax(min.Y, max.Y, p.Y)
+			min.Z, max.Z = minMax(min.Z, max.Z, p.Z)
+		}
+		info.Size = [3]float32{max.X - min.X, max.Y - min.Y, max.Z - min.Z}
+	}
+
+	for _, mat := range in.Materials {
+		mi := &MaterialInfo{Name: mat.Name, Shader: mat.ShaderName}
+		for _, property := range mat.Properties {
+			if !IsTextureProperty(property) {
+				continue
+			}
+			mi.Textures = append(mi.Textures, TextureDetail(property.Value, textureData(q, property)))
+		}
+		info.Materials = append(info.Materials, mi)
+	}
+	return info
+}
+
+// TextureDetail decodes data to report the format and dimensions of a texture
+func TextureDetail(name string, data []byte) *TextureInfo {
+	ti := &TextureInfo{Name: name, Size: len(data), Format: textureFormat(name, data)}
+	if len(data) == 0 {
+		ti.Format = "missing"
+		return ti
+	}
+	img, err := generateImage(name, data)
+	if err != nil || img == fallback() {
+		ti.Format += " (decode failed)"
+		return ti
+	}
+	ti.Width = img.Bounds().Dx()
+	ti.Height = img.Bounds().Dy()
+	return ti
+}
+
+// textureFormat returns the encoding of a texture, EQ bmp files are often dds data
+func textureFormat(name string, data []byte) string {
+	if len(data) >= 3 && string(data[0:3]) == "DDS" {
+		return "dds"
+	}
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+}
+
+// IsTextureProperty returns true if property names a texture
+func IsTextureProperty(property *common.MaterialProperty) bool {
+	return property.Category == 2 && strings.Contains(strings.ToLower(property.Name), "texture")
+}
+
+// textureData returns the embedded data of a texture property, or the archive file it names
+func textureData(q *quail.Quail, property *common.MaterialProperty) []byte {
+	if len(property.Data) > 0 {
+		return property.Data
+	}
+	for name, data := range q.Textures {
+		if strings.EqualFold(name, property.Value) {
+			return data
+		}
+	}
+	return nil
+}
+
+func minMax(min, max, value float32) (float32, float32) {
+	if value < min {
+		min = value
+	}
+	if value > max {
+		max = value
+	}
+	return min, max
+}
+
+// TextureImage decodes the texture named by property, a pink image is returned if it can't be decoded
+func TextureImage(q *quail.Quail, property *common.MaterialProperty) (*image.RGBA, error) {
+	return generateImage(property.Value, textureData(q, property))
+}
@@ -0,0 +1,48 @@
+package mesh
+
+import (
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/skin"
+)
+
+// PrepareSkin computes the bone indexes and weights Build uploads with the mesh of p, so a
+// rigged mesh can deform it. Vertices without influences, or whose bones are out of range,
+// follow the root bone
+func (p *Prepared) PrepareSkin(boneCount int, weights skin.Weights) {
+	p.skinIndex, p.skinValue = skinArrays(len(p.Model.Vertices), boneCount, weights)
+}
+
+// skinArrays returns the bone indexes and normalized weights of each vertex
+func skinArrays(vertexCount int, boneCount int, weights skin.Weights) (math32.ArrayF32, math32.ArrayF32) {
+	indices := math32.NewArrayF32(0, vertexCount*graphic.MaxBoneInfluencers)
+	values := math32.NewArrayF32(0, vertexCount*graphic.MaxBoneInfluencers)
+	for i := 0; i < vertexCount; i++ {
+		var bones, strengths [graphic.MaxBoneInfluencers]float32
+		total := float32(0)
+		slot := 0
+		if i < len(weights) {
+			for _, inf := range weights[i] {
+				if slot >= graphic.MaxBoneInfluencers || inf.Bone < 0 || inf.Bone >= boneCount {
+					continue
+				}
+				bones[slot] = float32(inf.Bone)
+				strengths[slot] = inf.Weight
+				total += inf.Weight
+				slot++
+			}
+		}
+		if total <= 0 {
+			bones = [graphic.MaxBoneInfluencers]float32{}
+			strengths = [graphic.MaxBoneInfluencers]float32{1}
+			total = 1
+		}
+		// weights are normalized so a vertex never drifts from its bones
+		for j := range strengths {
+			strengths[j] /= total
+		}
+		indices.Append(bones[:]...)
+		values.Append(strengths[:]...)
+	}
+	return indices, values
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/xackery/engine/camera"
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/experimental/collision"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/engine/window"
+	"github.com/xackery/quail-view/mesh"
+	"github.com/xackery/quail/common"
+)
+
+// pickDragMax is how far in pixels the cursor may move between press and release for a click to pick
+const pickDragMax = 4
+
+// setupPicking picks the triangle under the cursor when the viewport is clicked without dragging
+func (gv *g3nView) setupPicking() {
+	var downX, downY float32
+	gui.Manager().Subscribe(window.OnMouseDown, func(evname string, ev interface{}) {
+		mev := ev.(*window.MouseEvent)
+		downX, downY = mev.Xpos, mev.Ypos
+	})
+	gui.Manager().Subscribe(window.OnMouseUp, func(evname string, ev interface{}) {
+		mev := ev.(*window.MouseEvent)
+		if mev.Button != window.MouseButtonLeft {
+			return
+		}
+		if math32.Abs(mev.Xpos-downX) > pickDragMax || math32.Abs(mev.Ypos-downY) > pickDragMax {
+			return
+		}
+		gv.pick(mev.Xpos, mev.Ypos)
+	})
+
+	gv.pickInfo = NewInfoPanel(320, 200)
+	gv.scene.Add(gv.pickInfo)
+}
+
+// pick casts a ray from the cursor and reports the nearest triangle of the opened archive it hits
+func (gv *g3nView) pick(x, y float32) {
+	width, height := gv.GetSize()
+	sx := 2*x/float32(width) - 1
+	sy := -2*y/float32(height) + 1
+
+	cam := gv.activeCam()
+	rc := collision.NewRaycaster(&math32.Vector3{}, &math32.Vector3{})
+	if cam.Projection() == camera.Orthographic {
+		// orthographic rays are parallel, they start on the near plane under the cursor
+		origin := cam.Unproject(&math32.Vector3{X: sx, Y: sy, Z: -1})
+		far := cam.Unproject(&math32.Vector3{X: sx, Y: sy, Z: 1})
+		direction := far.Clone().Sub(origin).Normalize()
+		rc.Set(origin, direction)
+		cam.ViewMatrix(&rc.ViewMatrix)
+	} else {
+		err := rc.SetFromCamera(cam, sx, sy)
+		if err != nil {
+			fmt.Println("Failed to pick:", err)
+			return
+		}
+	}
+
+	hits := rc.IntersectObjects(gv.pickTargets(), false)
+	for _, hit := range hits {
+		node := hit.Object.GetNode()
+		name, ok := node.UserData().(string)
+		if !ok {
+			continue
+		}
+		model := gv.archiveModels[name]
+		if model == nil {
+			continue
+		}
+		triangle := int(hit.Index / 3)
+		if triangle >= len(model.Triangles) {
+			continue
+		}
+
+		lines := pickDetail(node.Name(), model, triangle, hit.Point)
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		gv.pickInfo.SetLines(lines)
+		_, height := gv.GetSize()
+		gv.pickInfo.SetPosition(10, float32(height)-gv.pickInfo.Height()-10)
+		gv.pickInfo.SetVisible(true)
+		gv.setHighlight(node, mesh.Highlight(model, []int{triangle}, math32.NewColor("Red")))
+		return
+	}
+	gv.pickInfo.SetVisible(false)
+}
+
+// pickTargets returns the meshes of the opened archive that can be picked
+func (gv *g3nView) pickTargets() []core.INode {
+	targets := []core.INode{}
+	var add func(inode core.INode)
+	add = func(inode core.INode) {
+		switch n := inode.(type) {
+		case *graphic.RiggedMesh:
+			// rigged meshes are not raycast, their embedded mesh is
+			targets = append(targets, n.Mesh)
+		case *graphic.Mesh:
+			if n == gv.highlight {
+				return
+			}
+			targets = append(targets, n)
+		default:
+			for _, child := range inode.GetNode().Children() {
+				add(child)
+			}
+		}
+	}
+	for _, node := range gv.archiveNodes {
+		add(node)
+	}
+	return targets
+}
+
+// pickDetail describes a triangle of model hit at point
+func pickDetail(nodeName string, model *common.Model, triangle int, point math32.Vector3) []string {
+	tri := model.Triangles[triangle]
+	lines := []string{
+		fmt.Sprintf("%s (%s)", nodeName, model.Header.Name),
+		fmt.Sprintf("Triangle %d, material %s, flag %d", triangle, tri.MaterialName, tri.Flag),
+		fmt.Sprintf("Hit %.3f, %.3f, %.3f", point.X, point.Y, point.Z),
+	}
+	if len(model.Bones) > 0 {
+		lines = append(lines, "Picked on the bind pose, animation is ignored")
+	}
+	for _, vi := range []uint32{tri.Index.X, tri.Index.Y, tri.Index.Z} {
+		if int(vi) >= len(model.Vertices) {
+			lines = append(lines, fmt.Sprintf("Vertex %d out of range", vi))
+			continue
+		}
+		v := model.Vertices[vi]
+		lines = append(lines, fmt.Sprintf("Vertex %d pos %.3f, %.3f, %.3f uv %.3f, %.3f",
+			vi, v.Position.X, v.Position.Y, v.Position.Z, v.Uv.X, v.Uv.Y))
+	}
+	return lines
+}
@@ -0,0 +1,30 @@
+package settings
+
+const (
+	// BackgroundSolid clears to a single color
+	BackgroundSolid = "solid"
+	// BackgroundGradient draws a vertical gradient dome behind the scene
+	BackgroundGradient = "gradient"
+	// BackgroundSkybox draws a sky model from an EQ sky archive behind the scene
+	BackgroundSkybox = "skybox"
+)
+
+// Background describes the viewer background
+type Background struct {
+	Mode     string     `json:"mode"`
+	Color    [3]float32 `json:"color"`
+	Top      [3]float32 `json:"top"`
+	Bottom   [3]float32 `json:"bottom"`
+	SkyPath  string     `json:"sky_path"`
+	SkyModel string     `json:"sky_model"`
+}
+
+// DefaultBackground returns the original dark gray background
+func DefaultBackground() *Background {
+	return &Background{
+		Mode:   BackgroundSolid,
+		Color:  [3]float32{0.2, 0.2, 0.2},
+		Top:    [3]float32{0.35, 0.4, 0.5},
+		Bottom: [3]float32{0.1, 0.1, 0.1},
+	}
+}
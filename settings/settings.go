@@ -0,0 +1,150 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/xackery/quail-view/bookmark"
+)
+
+// RecentMax is how many recently opened files are remembered
+const RecentMax = 10
+
+// ModelBudgetDefault is the GPU memory budget of archive models in megabytes
+const ModelBudgetDefault = 512
+
+// Settings are user preferences persisted between sessions
+type Settings struct {
+	ViewAxes     bool        `json:"view_axes"`
+	ViewGrid     bool        `json:"view_grid"`
+	ViewInfo     bool        `json:"view_info"`
+	FlyCamera    bool        `json:"fly_camera"`
+	FlySpeed     float32     `json:"fly_speed"`
+	Orthographic bool        `json:"orthographic"`
+	HotReload    bool        `json:"hot_reload"`
+	WindowWidth  int         `json:"window_width"`
+	WindowHeight int         `json:"window_height"`
+	FileDir      string      `json:"file_dir"`
+	EQPath       string      `json:"eq_path"`
+	Background   *Background `json:"background"`
+	Recent       []string    `json:"recent"`
+	Session      *Session    `json:"session"`
+	ModelBudget  int         `json:"model_budget"`
+	path         string
+}
+
+// Session is what was open when the viewer was last closed
+type Session struct {
+	Archive   string             `json:"archive"`
+	Focus     string             `json:"focus"`
+	Camera    *bookmark.Bookmark `json:"camera"`
+	Animation string             `json:"animation"`
+}
+
+// Default returns the settings used when no settings file exists
+func Default() *Settings {
+	return &Settings{
+		ViewAxes:     true,
+		ViewGrid:     true,
+		FlySpeed:     1,
+		HotReload:    true,
+		WindowWidth:  600,
+		WindowHeight: 600,
+		EQPath:       os.Getenv("EQ_PATH"),
+		Background:   DefaultBackground(),
+		ModelBudget:  ModelBudgetDefault,
+	}
+}
+
+// Dir returns the quail-view directory inside the user config directory
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("user config dir: %w", err)
+	}
+	return filepath.Join(dir, "quail-view"), nil
+}
+
+// Load reads settings from the user config directory, returning defaults if none are saved
+func Load() (*Settings, error) {
+	dir, err := Dir()
+	if err != nil {
+		return Default(), err
+	}
+	return LoadFile(filepath.Join(dir, "settings.json"))
+}
+
+// LoadFile reads settings from path, returning defaults if the file does not exist.
+// On error the defaults are returned too, still saving to path
+func LoadFile(path string) (*Settings, error) {
+	s := Default()
+	s.path = path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("read: %w", err)
+	}
+	err = json.Unmarshal(data, s)
+	if err != nil {
+		s = Default()
+		s.path = path
+		return s, fmt.Errorf("unmarshal %s: %w", path, err)
+	}
+	if s.Background == nil {
+		s.Background = DefaultBackground()
+	}
+	if s.WindowWidth <= 0 || s.WindowHeight <= 0 {
+		s.WindowWidth = 600
+		s.WindowHeight = 600
+	}
+	if s.EQPath == "" {
+		s.EQPath = os.Getenv("EQ_PATH")
+	}
+	if s.ModelBudget <= 0 {
+		s.ModelBudget = ModelBudgetDefault
+	}
+	return s, nil
+}
+
+// Save writes settings to the file they were loaded from
+func (s *Settings) Save() error {
+	if s.path == "" {
+		return fmt.Errorf("settings path not set")
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(s.path), 0755)
+	if err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	err = os.WriteFile(s.path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	return nil
+}
+
+// AddRecent moves path to the top of the recent files list
+func (s *Settings) AddRecent(path string) {
+	abs, err := filepath.Abs(path)
+	if err == nil {
+		path = abs
+	}
+	recent := []string{path}
+	for _, existing := range s.Recent {
+		if existing == path {
+			continue
+		}
+		recent = append(recent, existing)
+	}
+	if len(recent) > RecentMax {
+		recent = recent[:RecentMax]
+	}
+	s.Recent = recent
+}
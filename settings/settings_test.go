@@ -0,0 +1,79 @@
+package settings
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSettingsSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	s, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load missing: %s", err.Error())
+	}
+	if !s.ViewGrid || s.WindowWidth != 600 || s.ModelBudget != ModelBudgetDefault {
+		t.Fatalf("expected defaults, got %+v", s)
+	}
+
+	s.ViewGrid = false
+	s.WindowWidth = 800
+	s.Background.Mode = "gradient"
+	err = s.Save()
+	if err != nil {
+		t.Fatalf("save: %s", err.Error())
+	}
+
+	s, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %s", err.Error())
+	}
+	if s.ViewGrid || s.WindowWidth != 800 || s.Background.Mode != "gradient" {
+		t.Fatalf("settings not restored, got %+v", s)
+	}
+}
+
+func TestSettingsLoadCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	err := os.WriteFile(path, []byte("{"), 0644)
+	if err != nil {
+		t.Fatalf("write: %s", err.Error())
+	}
+	s, err := LoadFile(path)
+	if err == nil {
+		t.Fatalf("expected an error loading corrupt settings")
+	}
+	if s == nil || s.WindowWidth != 600 {
+		t.Fatalf("expected defaults, got %+v", s)
+	}
+	// the defaults replace the corrupt file once saved
+	err = s.Save()
+	if err != nil {
+		t.Fatalf("save: %s", err.Error())
+	}
+	_, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %s", err.Error())
+	}
+}
+
+func TestSettingsAddRecent(t *testing.T) {
+	s := Default()
+	for i := 0; i < RecentMax+2; i++ {
+		s.AddRecent(fmt.Sprintf("/eq/%d.eqg", i))
+	}
+	s.AddRecent("/eq/5.eqg")
+
+	if len(s.Recent) != RecentMax {
+		t.Fatalf("expected %d recent, got %d", RecentMax, len(s.Recent))
+	}
+	if s.Recent[0] != "/eq/5.eqg" {
+		t.Fatalf("expected /eq/5.eqg first, got %s", s.Recent[0])
+	}
+	for i, path := range s.Recent[1:] {
+		if path == "/eq/5.eqg" {
+			t.Fatalf("duplicate recent at %d", i+1)
+		}
+	}
+}
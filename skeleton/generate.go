@@ -1,7 +1,7 @@
 package skeleton
 
 import (
-	"sort"
+	"fmt"
 
 	"github.com/xackery/engine/core"
 	"github.com/xackery/engine/graphic"
@@ -9,82 +9,58 @@ import (
 	"github.com/xackery/quail/common"
 )
 
-type jointEntry struct {
-	index int
-	node  *core.Node
-	bone  common.Bone
-}
-
-var (
-	joints []*jointEntry
-)
-
+// Generate returns a skeleton with one joint per bone, in the same order as in,
+// so vertex bone indexes address the matching joint
 func Generate(in []common.Bone) (*graphic.Skeleton, error) {
+	if len(in) == 0 {
+		return nil, fmt.Errorf("no bones")
+	}
 	skel := graphic.NewSkeleton()
 
-	joints = make([]*jointEntry, 0)
-
-	rootBone := core.NewNode()
-	rootBone.SetName("root")
-	traverse(in, rootBone, in[0])
-
-	sort.Slice(joints, func(i, j int) bool {
-		return joints[i].index < joints[j].index
-	})
-
-	for _, joint := range joints {
-		tm := math32.NewMatrix4()
-		var pivot math32.Vector3
-		pivot.X = joint.bone.Pivot.X
-		pivot.Y = joint.bone.Pivot.Y
-		pivot.Z = joint.bone.Pivot.Z
-		var rotation math32.Quaternion
-		rotation.X = joint.bone.Rotation.X
-		rotation.Y = joint.bone.Rotation.Y
-		rotation.Z = joint.bone.Rotation.Z
-		rotation.W = joint.bone.Rotation.W
-		var scale math32.Vector3
-		scale.X = joint.bone.Scale.X
-		scale.Y = joint.bone.Scale.Y
-		scale.Z = joint.bone.Scale.Z
-		tm.Compose(&pivot, &rotation, &scale)
+	nodes := make([]*core.Node, len(in))
+	for i, bone := range in {
+		node := core.NewNode()
+		node.SetName(bone.Name)
+		pivot, rotation, scale := localTransform(bone)
+		node.SetPositionVec(&pivot)
+		node.SetQuaternionQuat(&rotation)
+		node.SetScaleVec(&scale)
+		nodes[i] = node
+	}
+	for i, parent := range Parents(in) {
+		if parent >= 0 {
+			nodes[parent].Add(nodes[i])
+		}
+	}
 
+	pose := BindPose(in)
+	for i, node := range nodes {
 		ibm := math32.NewMatrix4()
-		ibm.GetInverse(tm)
-
-		skel.AddBone(joint.node, ibm)
+		err := ibm.GetInverse(&pose[i])
+		if err != nil {
+			return nil, fmt.Errorf("bone %s inverse bind matrix: %w", in[i].Name, err)
+		}
+		skel.AddBone(node, ibm)
 	}
-
 	return skel, nil
 }
 
-func traverse(bones []common.Bone, ptr *core.Node, focus common.Bone) {
-	if focus.ChildrenCount > 0 {
-		child := bones[focus.ChildIndex]
-		childNode := core.NewNode()
-		childNode.SetName(child.Name)
-
-		ptr.Add(childNode)
-		je := &jointEntry{
-			index: int(focus.ChildIndex),
-			node:  childNode,
-			bone:  child,
+// Attach adds the root joints of skel to node, so joints follow the mesh they deform
+func Attach(skel *graphic.Skeleton, node core.INode) {
+	for _, joint := range skel.Bones() {
+		if joint.Parent() == nil {
+			node.GetNode().Add(joint)
 		}
-		joints = append(joints, je)
-		traverse(bones, childNode, child)
 	}
+}
 
-	if focus.Next > -1 {
-		next := bones[focus.Next]
-		nextNode := core.NewNode()
-		nextNode.SetName(next.Name)
-		ptr.Add(nextNode)
-		je := &jointEntry{
-			index: int(focus.Next),
-			node:  nextNode,
-			bone:  next,
-		}
-		joints = append(joints, je)
-		traverse(bones, nextNode, next)
+// localTransform returns the bind transform of bone relative to its parent
+func localTransform(bone common.Bone) (math32.Vector3, math32.Quaternion, math32.Vector3) {
+	pivot := math32.Vector3{X: bone.Pivot.X, Y: bone.Pivot.Y, Z: bone.Pivot.Z}
+	rotation := math32.Quaternion{X: bone.Rotation.X, Y: bone.Rotation.Y, Z: bone.Rotation.Z, W: bone.Rotation.W}
+	scale := math32.Vector3{X: bone.Scale.X, Y: bone.Scale.Y, Z: bone.Scale.Z}
+	if scale.X == 0 && scale.Y == 0 && scale.Z == 0 {
+		scale.Set(1, 1, 1)
 	}
+	return pivot, rotation, scale
 }
@@ -0,0 +1,33 @@
+package skeleton
+
+import (
+	"testing"
+
+	"github.com/xackery/engine/core"
+)
+
+func TestGenerate(t *testing.T) {
+	bones := testBones()
+	skel, err := Generate(bones)
+	if err != nil {
+		t.Fatalf("generate: %s", err)
+	}
+	joints := skel.Bones()
+	if len(joints) != len(bones) {
+		t.Fatalf("%d joints, expected %d", len(joints), len(bones))
+	}
+	for i, joint := range joints {
+		if joint.Name() != bones[i].Name {
+			t.Fatalf("joint %d named %s, expected %s", i, joint.Name(), bones[i].Name)
+		}
+	}
+	if joints[3].Parent() != joints[1] {
+		t.Fatalf("head is not attached to spine")
+	}
+
+	root := core.NewNode()
+	Attach(skel, root)
+	if len(root.Children()) != 1 {
+		t.Fatalf("%d root joints attached, expected 1", len(root.Children()))
+	}
+}
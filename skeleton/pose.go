@@ -0,0 +1,61 @@
+package skeleton
+
+import (
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail/common"
+)
+
+// Parents returns the parent index of each bone, -1 for bones without a parent.
+// Bones list their first child and their next sibling
+func Parents(bones []common.Bone) []int {
+	parents := make([]int, len(bones))
+	for i := range parents {
+		parents[i] = -1
+	}
+	visited := make([]bool, len(bones))
+	var walk func(i int, parent int)
+	walk = func(i int, parent int) {
+		for i >= 0 && i < len(bones) && !visited[i] {
+			visited[i] = true
+			parents[i] = parent
+			bone := bones[i]
+			if bone.ChildrenCount > 0 {
+				walk(int(bone.ChildIndex), i)
+			}
+			i = int(bone.Next)
+		}
+	}
+	if len(bones) > 0 {
+		walk(0, -1)
+	}
+	return parents
+}
+
+// BindPose returns the model space transform of each bone
+func BindPose(bones []common.Bone) []math32.Matrix4 {
+	parents := Parents(bones)
+	world := make([]math32.Matrix4, len(bones))
+	done := make([]bool, len(bones))
+
+	var resolve func(i int) *math32.Matrix4
+	resolve = func(i int) *math32.Matrix4 {
+		if done[i] {
+			return &world[i]
+		}
+		// mark first so a malformed parent loop can't recurse forever
+		done[i] = true
+		pivot, rotation, scale := localTransform(bones[i])
+		var local math32.Matrix4
+		local.Compose(&pivot, &rotation, &scale)
+		if parents[i] < 0 {
+			world[i] = local
+			return &world[i]
+		}
+		world[i].MultiplyMatrices(resolve(parents[i]), &local)
+		return &world[i]
+	}
+	for i := range bones {
+		resolve(i)
+	}
+	return world
+}
@@ -0,0 +1,50 @@
+package skeleton
+
+import (
+	"testing"
+
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail-view/internal/fixture"
+	"github.com/xackery/quail/common"
+)
+
+// testBones is a root with a spine and tail, and a head on the spine
+func testBones() []common.Bone {
+	identity := common.Quad4{W: 1}
+	one := common.Vector3{X: 1, Y: 1, Z: 1}
+	return []common.Bone{
+		{Name: "root", Next: -1, ChildrenCount: 2, ChildIndex: 1, Rotation: identity, Scale: one},
+		{Name: "spine", Next: 2, ChildrenCount: 1, ChildIndex: 3, Pivot: common.Vector3{Y: 1}, Rotation: identity, Scale: one},
+		{Name: "tail", Next: -1, Pivot: common.Vector3{Z: -1}, Rotation: identity, Scale: one},
+		{Name: "head", Next: -1, Pivot: common.Vector3{Y: 2}, Rotation: identity, Scale: one},
+	}
+}
+
+func TestBindPose(t *testing.T) {
+	bones := testBones()
+
+	parents := Parents(bones)
+	expected := []int{-1, 0, 0, 1}
+	for i := range expected {
+		if parents[i] != expected[i] {
+			t.Fatalf("bone %d parent %d, expected %d", i, parents[i], expected[i])
+		}
+	}
+
+	pose := BindPose(bones)
+	var head math32.Vector3
+	head.SetFromMatrixPosition(&pose[3])
+	if head.Y != 3 {
+		t.Fatalf("head at %v, expected y 3", head)
+	}
+}
+
+func TestBindPoseChain(t *testing.T) {
+	bones := fixture.BoneChain("chain", 3, 0.5)
+	pose := BindPose(bones)
+	var tip math32.Vector3
+	tip.SetFromMatrixPosition(&pose[2])
+	if tip.Y != 1 {
+		t.Fatalf("chain tip at %v, expected y 1", tip)
+	}
+}
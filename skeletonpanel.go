@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xackery/engine/app"
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/geometry"
+	"github.com/xackery/engine/gls"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/material"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/engine/window"
+	"github.com/xackery/quail-view/mesh"
+	"github.com/xackery/quail-view/skeleton"
+)
+
+// boneHoverDistance is how close in pixels the cursor must be to a joint to show its name
+const boneHoverDistance = 10
+
+type SkeletonPanel struct {
+	gui.Panel
+	ddBone   *gui.DropDown
+	status   *gui.Label
+	bclose   *gui.Button
+	updating bool
+}
+
+func NewSkeletonPanel(width, height float32) *SkeletonPanel {
+
+	sp := new(SkeletonPanel)
+	sp.Panel.Initialize(sp, width, height)
+	sp.SetBorders(2, 2, 2, 2)
+	sp.SetPaddings(4, 4, 4, 4)
+	sp.SetColor(math32.NewColor("White"))
+	sp.SetVisible(false)
+	sp.SetBounded(false)
+
+	// Set vertical box layout for the whole panel
+	l := gui.NewVBoxLayout()
+	l.SetSpacing(4)
+	sp.SetLayout(l)
+
+	sp.Add(gui.NewLabel("Weights of bone"))
+	sp.ddBone = gui.NewDropDown(width-16, gui.NewImageLabel(""))
+	sp.ddBone.Subscribe(gui.OnChange, func(evname string, ev interface{}) {
+		if sp.updating {
+			return
+		}
+		// the first entry shows no weights
+		sp.Dispatch("OnBone", sp.ddBone.SelectedPos()-1)
+	})
+	sp.Add(sp.ddBone)
+
+	sp.status = gui.NewLabel("")
+	sp.Add(sp.status)
+
+	// Creates Close button
+	sp.bclose = gui.NewButton("Close")
+	sp.bclose.SetLayoutParams(&gui.VBoxLayoutParams{Expand: 0, AlignH: gui.AlignCenter})
+	sp.bclose.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		sp.Dispatch("OnClose", nil)
+	})
+	sp.Add(sp.bclose)
+
+	return sp
+}
+
+// Show shows or hide the skeleton panel
+func (sp *SkeletonPanel) Show(show bool) {
+
+	if !show {
+		sp.SetVisible(false)
+		return
+	}
+	sp.SetVisible(true)
+	width, _ := app.App(300, 300, "Skeleton").GetSize()
+	sp.SetPosition(float32(width)-sp.Width()-10, 30)
+}
+
+// SetBones lists bone names in the bone drop down
+func (sp *SkeletonPanel) SetBones(names []string) {
+	sp.updating = true
+	defer func() { sp.updating = false }()
+
+	for sp.ddBone.Len() > 0 {
+		sp.ddBone.RemoveAt(0)
+	}
+	sp.ddBone.Add(gui.NewImageLabel("(none)"))
+	for _, name := range names {
+		sp.ddBone.Add(gui.NewImageLabel(name))
+	}
+	sp.ddBone.SelectPos(0)
+}
+
+// SetStatus sets the status line text
+func (sp *SkeletonPanel) SetStatus(text string) {
+	sp.status.SetText(text)
+}
+
+// skeletonOverlay is the drawn skeleton of the focused model
+type skeletonOverlay struct {
+	node    *core.Node
+	rig     *graphic.RiggedMesh
+	parents []int
+	names   []string
+	joints  []math32.Vector3 // Model space joint positions of the current pose
+	spheres []*graphic.Mesh
+	lines   *gls.VBO
+}
+
+// buildSkeletonPanel creates the skeleton panel and bone name hover label
+func (gv *g3nView) buildSkeletonPanel() {
+	gv.sp = NewSkeletonPanel(260, 140)
+	gv.sp.Subscribe("OnBone", func(evname string, ev interface{}) {
+		gv.showBoneWeights(ev.(int))
+	})
+	gv.sp.Subscribe("OnClose", func(evname string, ev interface{}) {
+		gv.sp.Show(false)
+		gv.refreshSkeleton()
+	})
+	gv.scene.Add(gv.sp)
+
+	gv.boneLabel = gui.NewLabel("")
+	gv.boneLabel.SetColor4(&math32.Color4{R: 1, G: 1, B: 0.6, A: 1})
+	gv.boneLabel.SetVisible(false)
+	gv.scene.Add(gv.boneLabel)
+
+	gui.Manager().Subscribe(window.OnCursor, func(evname string, ev interface{}) {
+		cev := ev.(*window.CursorEvent)
+		gv.hoverBone(cev.Xpos, cev.Ypos)
+	})
+}
+
+// clearSkeleton removes the drawn skeleton
+func (gv *g3nView) clearSkeleton() {
+	gv.boneLabel.SetVisible(false)
+	if gv.skel == nil {
+		return
+	}
+	if parent := gv.skel.node.Parent(); parent != nil {
+		parent.GetNode().Remove(gv.skel.node)
+	}
+	gv.skel.node.DisposeChildren(true)
+	gv.skel = nil
+}
+
+// refreshSkeleton draws the skeleton of the focused model while the skeleton panel is open
+func (gv *g3nView) refreshSkeleton() {
+	gv.clearSkeleton()
+
+	model := gv.archiveModels[gv.focusName]
+	node := gv.focusedNode()
+	if !gv.sp.Visible() || model == nil || node == nil {
+		return
+	}
+	if len(model.Bones) == 0 {
+		gv.sp.SetBones(nil)
+		gv.sp.SetStatus("Model has no bones")
+		return
+	}
+
+	var am *archiveModel
+	if gv.loader != nil {
+		am = gv.loader.model(gv.focusName)
+	}
+	if am == nil || am.rig == nil {
+		gv.sp.SetBones(nil)
+		gv.sp.SetStatus("Model has no rig")
+		return
+	}
+
+	overlay := &skeletonOverlay{
+		node:    core.NewNode(),
+		rig:     am.rig,
+		parents: skeleton.Parents(model.Bones),
+		joints:  make([]math32.Vector3, len(model.Bones)),
+	}
+	for _, bone := range model.Bones {
+		overlay.names = append(overlay.names, bone.Name)
+	}
+
+	// bones are drawn over the mesh so they stay visible inside it
+	colors := math32.NewArrayF32(0, len(overlay.parents)*6)
+	for _, parent := range overlay.parents {
+		if parent >= 0 {
+			colors.Append(1, 1, 0, 1, 0.5, 0)
+		}
+	}
+	overlay.lines = gls.NewVBO(math32.NewArrayF32(0, len(overlay.parents)*6)).AddAttrib(gls.VertexPosition)
+	geom := geometry.NewGeometry()
+	geom.AddVBO(overlay.lines)
+	geom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+	lineMat := material.NewBasic()
+	lineMat.SetDepthTest(false)
+	overlay.node.Add(graphic.NewLines(geom, lineMat))
+
+	bounds := mesh.Bounds(model)
+	size := bounds.Size(nil)
+	radius := math32.Max(size.X, math32.Max(size.Y, size.Z)) / 100
+	jointGeom := geometry.NewSphere(float64(math32.Max(radius, 0.01)), 8, 6)
+	jointMat := material.NewStandard(math32.NewColor("Orange"))
+	jointMat.SetDepthTest(false)
+	for i := range overlay.joints {
+		// joints share one geometry and material, counted once per joint for disposal
+		if i > 0 {
+			jointGeom.Incref()
+			jointMat.Incref()
+		}
+		joint := graphic.NewMesh(jointGeom, jointMat)
+		overlay.spheres = append(overlay.spheres, joint)
+		overlay.node.Add(joint)
+	}
+	overlay.pose()
+
+	node.Add(overlay.node)
+	gv.skel = overlay
+	gv.sp.SetBones(overlay.names)
+	gv.sp.SetStatus(fmt.Sprintf("%d bones", len(model.Bones)))
+}
+
+// updateSkeleton moves the drawn skeleton to the current pose of the focused model, called each frame
+func (gv *g3nView) updateSkeleton() {
+	if gv.skel == nil {
+		return
+	}
+	gv.skel.pose()
+}
+
+// pose places the joints and bones at the current world positions of the rig's bones
+func (o *skeletonOverlay) pose() {
+	o.rig.UpdateMatrixWorld()
+	rigWorld := o.rig.MatrixWorld()
+	var inverse math32.Matrix4
+	if inverse.GetInverse(&rigWorld) != nil {
+		return
+	}
+	for i, bone := range o.rig.Skeleton().Bones() {
+		if i >= len(o.joints) {
+			break
+		}
+		// the overlay is a child of the rig, joints are kept relative to it
+		boneWorld := bone.MatrixWorld()
+		var local math32.Matrix4
+		local.MultiplyMatrices(&inverse, &boneWorld)
+		o.joints[i].SetFromMatrixPosition(&local)
+		o.spheres[i].SetPositionVec(&o.joints[i])
+	}
+	positions := o.lines.Buffer()
+	*positions = (*positions)[:0]
+	for i, parent := range o.parents {
+		if parent < 0 {
+			continue
+		}
+		a, b := o.joints[parent], o.joints[i]
+		positions.Append(a.X, a.Y, a.Z, b.X, b.Y, b.Z)
+	}
+	o.lines.Update()
+}
+
+// showBoneWeights colors the focused model by how strongly each vertex follows bone
+func (gv *g3nView) showBoneWeights(bone int) {
+	gv.clearHighlight()
+	model := gv.archiveModels[gv.focusName]
+	if bone < 0 || model == nil {
+		return
+	}
+	weights := gv.archiveWeights[strings.ToLower(model.Header.Name)]
+	if len(weights) == 0 {
+		gv.sp.SetStatus("No weights stored for this model")
+		return
+	}
+	boneWeights := weights.BoneWeights(bone)
+	count := 0
+	for _, w := range boneWeights {
+		if w > 0 {
+			count++
+		}
+	}
+	gv.sp.SetStatus(fmt.Sprintf("%d vertices weighted to %s", count, model.Bones[bone].Name))
+	gv.setHighlight(gv.focusedNode(), mesh.Heatmap(model, boneWeights))
+}
+
+// hoverBone shows the name of the joint under the cursor
+func (gv *g3nView) hoverBone(x, y float32) {
+	if gv.skel == nil {
+		return
+	}
+	width, height := gv.GetSize()
+	matrixWorld := gv.skel.node.MatrixWorld()
+	best := -1
+	bestDist := float32(boneHoverDistance)
+	for i, joint := range gv.skel.joints {
+		pos := joint
+		pos.ApplyMatrix4(&matrixWorld)
+		gv.activeCam().Project(&pos)
+		if pos.Z < -1 || pos.Z > 1 {
+			continue
+		}
+		sx := (pos.X + 1) / 2 * float32(width)
+		sy := (1 - pos.Y) / 2 * float32(height)
+		dist := math32.Sqrt((sx-x)*(sx-x) + (sy-y)*(sy-y))
+		if dist < bestDist {
+			best = i
+			bestDist = dist
+		}
+	}
+	if best < 0 {
+		gv.boneLabel.SetVisible(false)
+		return
+	}
+	gv.boneLabel.SetText(gv.skel.names[best])
+	gv.boneLabel.SetPosition(x+12, y+12)
+	gv.boneLabel.SetVisible(true)
+}
@@ -0,0 +1,257 @@
+// Package skin reads per vertex bone weights from EQG model files and S3D meshes, which
+// quail does not decode
+package skin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/quail/pfs"
+)
+
+// influenceSlots is how many bone influences an EQG vertex stores
+const influenceSlots = 4
+
+// Influence is how strongly a vertex follows a bone
+type Influence struct {
+	Bone   int
+	Weight float32
+}
+
+// Weights holds the bone influences of each vertex of a model
+type Weights [][]Influence
+
+// BoneWeights returns how strongly each vertex follows bone
+func (w Weights) BoneWeights(bone int) []float32 {
+	weights := make([]float32, len(w))
+	for i, influences := range w {
+		for _, inf := range influences {
+			if inf.Bone == bone {
+				weights[i] += inf.Weight
+			}
+		}
+	}
+	return weights
+}
+
+// ReadAll returns the weights of every model inside the archive at path, keyed by
+// lower case model name. Files that fail to decode are skipped with a warning
+func ReadAll(path string) (map[string]Weights, error) {
+	archive, err := pfs.NewFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("pfs open: %w", err)
+	}
+	all := make(map[string]Weights)
+	files := archive.Files()
+	for i := range files {
+		name := strings.ToLower(files[i].Name())
+		ext := filepath.Ext(name)
+		if ext == ".wld" {
+			meshes, err := DecodeWld(files[i].Data())
+			if err != nil {
+				fmt.Println("skin: skipping", name, err)
+				continue
+			}
+			for meshName, weights := range meshes {
+				all[meshName] = weights
+			}
+			continue
+		}
+		if ext != ".mod" && ext != ".mds" {
+			continue
+		}
+		weights, err := Decode(files[i].Data())
+		if err != nil {
+			fmt.Println("skin: skipping", name, err)
+			continue
+		}
+		if len(weights) == 0 {
+			continue
+		}
+		all[strings.TrimSuffix(name, ext)] = weights
+	}
+	return all, nil
+}
+
+// Decode reads the bone assignments of a MOD or MDS file
+func Decode(data []byte) (Weights, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("file too short")
+	}
+	r := &reader{r: bytes.NewReader(data)}
+	header := string(data[0:4])
+	r.skip(4)
+	switch header {
+	case "EQGM":
+		return decodeMod(r)
+	case "EQGS":
+		return decodeMds(r)
+	}
+	return nil, fmt.Errorf("unknown header %s", header)
+}
+
+// decodeMod reads a MOD file, where weights follow the bones
+func decodeMod(r *reader) (Weights, error) {
+	version := r.uint32()
+	nameLength := r.uint32()
+	materialCount := r.uint32()
+	vertexCount := r.uint32()
+	triangleCount := r.uint32()
+	boneCount := r.uint32()
+	r.skip(int64(nameLength))
+	r.skipMaterials(materialCount)
+	r.skipVertices(version, vertexCount)
+	r.skip(int64(triangleCount) * 20)
+	r.skip(int64(boneCount) * 56)
+	if boneCount == 0 {
+		return nil, r.err
+	}
+	return r.weights(vertexCount)
+}
+
+// decodeMds reads an MDS file, where weights follow the triangles of each sub model.
+// The weights of all sub models are concatenated in vertex order
+func decodeMds(r *reader) (Weights, error) {
+	version := r.uint32()
+	nameLength := r.uint32()
+	materialCount := r.uint32()
+	boneCount := r.uint32()
+	subCount := r.uint32()
+	r.skip(int64(nameLength))
+	r.skipMaterials(materialCount)
+	r.skip(int64(boneCount) * 56)
+	w := Weights{}
+	for i := uint32(0); i < subCount && r.err == nil; i++ {
+		r.uint32() // main name index
+		r.uint32() // sub name index
+		vertexCount := r.uint32()
+		triangleCount := r.uint32()
+		assignmentCount := r.uint32()
+		r.skipVertices(version, vertexCount)
+		r.skip(int64(triangleCount) * 20)
+		sub, err := r.weights(assignmentCount)
+		if err != nil {
+			return nil, fmt.Errorf("sub model %d: %w", i, err)
+		}
+		// sub models without assignments still take up their vertices
+		for len(sub) < int(vertexCount) {
+			sub = append(sub, []Influence{})
+		}
+		w = append(w, sub[:vertexCount]...)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("read sub models: %w", r.err)
+	}
+	if boneCount == 0 {
+		return nil, nil
+	}
+	return w, nil
+}
+
+// reader reads little endian values, keeping the first error
+type reader struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (r *reader) uint32() uint32 {
+	var value uint32
+	if r.err != nil {
+		return 0
+	}
+	r.err = binary.Read(r.r, binary.LittleEndian, &value)
+	return value
+}
+
+func (r *reader) uint16() uint16 {
+	var value uint16
+	if r.err != nil {
+		return 0
+	}
+	r.err = binary.Read(r.r, binary.LittleEndian, &value)
+	return value
+}
+
+func (r *reader) int32() int32 {
+	var value int32
+	if r.err != nil {
+		return 0
+	}
+	r.err = binary.Read(r.r, binary.LittleEndian, &value)
+	return value
+}
+
+func (r *reader) float32() float32 {
+	var value float32
+	if r.err != nil {
+		return 0
+	}
+	r.err = binary.Read(r.r, binary.LittleEndian, &value)
+	return value
+}
+
+func (r *reader) bytes(n uint32) []byte {
+	if r.err != nil || int64(n) > int64(r.r.Len()) {
+		if r.err == nil {
+			r.err = io.ErrUnexpectedEOF
+		}
+		return nil
+	}
+	data := make([]byte, n)
+	_, r.err = io.ReadFull(r.r, data)
+	return data
+}
+
+func (r *reader) skip(n int64) {
+	if r.err != nil {
+		return
+	}
+	if n > int64(r.r.Len()) {
+		r.err = io.ErrUnexpectedEOF
+		return
+	}
+	_, r.err = r.r.Seek(n, io.SeekCurrent)
+}
+
+// skipMaterials skips material entries, each followed by its properties
+func (r *reader) skipMaterials(count uint32) {
+	for i := uint32(0); i < count && r.err == nil; i++ {
+		r.skip(12) // id, name and shader offsets
+		propertyCount := r.uint32()
+		r.skip(int64(propertyCount) * 12)
+	}
+}
+
+// skipVertices skips vertices, which gained a tint and second uv after version 2
+func (r *reader) skipVertices(version uint32, count uint32) {
+	size := int64(32)
+	if version > 2 {
+		size = 44
+	}
+	r.skip(int64(count) * size)
+}
+
+// weights reads count bone assignments of influenceSlots entries each
+func (r *reader) weights(count uint32) (Weights, error) {
+	w := make(Weights, 0, count)
+	for i := uint32(0); i < count; i++ {
+		used := r.uint32()
+		influences := []Influence{}
+		for j := uint32(0); j < influenceSlots; j++ {
+			bone := r.uint32()
+			weight := r.float32()
+			if j < used && weight > 0 {
+				influences = append(influences, Influence{Bone: int(bone), Weight: weight})
+			}
+		}
+		w = append(w, influences)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("read weights: %w", r.err)
+	}
+	return w, nil
+}
@@ -0,0 +1,123 @@
+package skin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestDecodeMod(t *testing.T) {
+	buf := &bytes.Buffer{}
+	write := func(values ...interface{}) {
+		for _, v := range values {
+			binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+
+	names := []byte("mat\x00shader\x00bone\x00")
+	buf.WriteString("EQGM")
+	// version, name length, materials, vertices, triangles, bones
+	write(uint32(1), uint32(len(names)), uint32(1), uint32(2), uint32(1), uint32(1))
+	buf.Write(names)
+	// material with one property
+	write(int32(0), uint32(0), uint32(4), uint32(1), uint32(0), uint32(0), float32(1))
+	// vertices, version 1 has no tint or second uv
+	buf.Write(make([]byte, 2*32))
+	// triangle
+	buf.Write(make([]byte, 20))
+	// bone
+	buf.Write(make([]byte, 56))
+	// weights, the second vertex is split across two bones
+	write(uint32(1), uint32(0), float32(1), uint32(0), float32(0), uint32(0), float32(0), uint32(0), float32(0))
+	write(uint32(2), uint32(0), float32(0.25), uint32(1), float32(0.75), uint32(0), float32(0), uint32(0), float32(0))
+
+	w, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("decode: %s", err.Error())
+	}
+	if len(w) != 2 || len(w[0]) != 1 || len(w[1]) != 2 {
+		t.Fatalf("unexpected weights %+v", w)
+	}
+	bone1 := w.BoneWeights(1)
+	if bone1[0] != 0 || bone1[1] != 0.75 {
+		t.Fatalf("unexpected bone 1 weights %v", bone1)
+	}
+}
+
+func TestDecodeMds(t *testing.T) {
+	buf := &bytes.Buffer{}
+	write := func(values ...interface{}) {
+		for _, v := range values {
+			binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+
+	buf.WriteString("EQGS")
+	// version, name length, materials, bones, sub models
+	write(uint32(1), uint32(0), uint32(0), uint32(2), uint32(2))
+	buf.Write(make([]byte, 2*56))
+	// the first sub model has one vertex bound to bone 0
+	write(uint32(0), uint32(0), uint32(1), uint32(0), uint32(1))
+	buf.Write(make([]byte, 32))
+	write(uint32(1), uint32(0), float32(1), uint32(0), float32(0), uint32(0), float32(0), uint32(0), float32(0))
+	// the second has two vertices, only the first has an assignment
+	write(uint32(0), uint32(0), uint32(2), uint32(0), uint32(1))
+	buf.Write(make([]byte, 2*32))
+	write(uint32(1), uint32(1), float32(1), uint32(0), float32(0), uint32(0), float32(0), uint32(0), float32(0))
+
+	w, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatalf("decode: %s", err.Error())
+	}
+	if len(w) != 3 {
+		t.Fatalf("%d vertices, expected 3", len(w))
+	}
+	bone1 := w.BoneWeights(1)
+	if bone1[0] != 0 || bone1[1] != 1 || bone1[2] != 0 {
+		t.Fatalf("unexpected bone 1 weights %v", bone1)
+	}
+}
+
+func TestDecodeWld(t *testing.T) {
+	frag := &bytes.Buffer{}
+	write := func(buf *bytes.Buffer, values ...interface{}) {
+		for _, v := range values {
+			binary.Write(buf, binary.LittleEndian, v)
+		}
+	}
+
+	// mesh header up to the counts
+	write(frag, int32(-1))
+	frag.Write(make([]byte, 72))
+	// vertices, uvs, normals, colors, triangles, pieces, then the rest
+	write(frag, uint16(3), uint16(0), uint16(0), uint16(0), uint16(1), uint16(2), uint16(0), uint16(0), uint16(0), uint16(0))
+	frag.Write(make([]byte, 3*6))
+	frag.Write(make([]byte, 8))
+	// two vertices on bone 0, one on bone 4
+	write(frag, uint16(2), uint16(0), uint16(1), uint16(4))
+
+	names := []byte("\x00ELF_DMSPRITEDEF\x00")
+	for i := range names {
+		names[i] ^= wldHashKey[i%len(wldHashKey)]
+	}
+	buf := &bytes.Buffer{}
+	buf.Write([]byte{0x02, 0x3D, 0x50, 0x54})
+	// version, fragments, regions, max object size, hash size, unknown
+	write(buf, uint32(0x1000C800), uint32(1), uint32(0), uint32(0), uint32(len(names)), uint32(0))
+	buf.Write(names)
+	write(buf, uint32(frag.Len()), uint32(0x36))
+	buf.Write(frag.Bytes())
+
+	meshes, err := DecodeWld(buf.Bytes())
+	if err != nil {
+		t.Fatalf("decode: %s", err.Error())
+	}
+	w, ok := meshes["elf"]
+	if !ok || len(w) != 3 {
+		t.Fatalf("unexpected meshes %+v", meshes)
+	}
+	bone4 := w.BoneWeights(4)
+	if bone4[0] != 0 || bone4[1] != 0 || bone4[2] != 1 {
+		t.Fatalf("unexpected bone 4 weights %v", bone4)
+	}
+}
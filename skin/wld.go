@@ -0,0 +1,111 @@
+package skin
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	wldOldVersion = 0x00015500
+	wldMesh       = 0x36
+)
+
+// wldHashKey decodes the xor encoded string table of a WLD file
+var wldHashKey = []byte{0x95, 0x3A, 0xC5, 0x2A, 0x95, 0x7A, 0x95, 0x6A}
+
+// DecodeWld reads the vertex pieces of every mesh in a WLD file, keyed by lower case
+// mesh name without its _dmspritedef suffix. Each piece binds a run of vertices fully
+// to one bone. Meshes without pieces, such as zone geometry, are left out
+func DecodeWld(data []byte) (map[string]Weights, error) {
+	if len(data) < 4 || !bytes.Equal(data[0:4], []byte{0x02, 0x3D, 0x50, 0x54}) {
+		return nil, fmt.Errorf("unknown header")
+	}
+	r := &reader{r: bytes.NewReader(data)}
+	r.skip(4)
+	version := r.uint32()
+	fragmentCount := r.uint32()
+	r.skip(8) // region count, max object size
+	hashSize := r.uint32()
+	r.skip(4)
+	hash := r.bytes(hashSize)
+	for i := range hash {
+		hash[i] ^= wldHashKey[i%len(wldHashKey)]
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("read header: %w", r.err)
+	}
+
+	all := make(map[string]Weights)
+	for i := uint32(0); i < fragmentCount; i++ {
+		size := r.uint32()
+		code := r.uint32()
+		frag := r.bytes(size)
+		if r.err != nil {
+			return nil, fmt.Errorf("fragment %d: %w", i, r.err)
+		}
+		if code != wldMesh {
+			continue
+		}
+		nameRef, weights, err := decodeWldMesh(frag, version == wldOldVersion)
+		if err != nil {
+			return nil, fmt.Errorf("fragment %d mesh: %w", i, err)
+		}
+		if len(weights) == 0 {
+			continue
+		}
+		name := strings.ToLower(wldName(hash, nameRef))
+		all[strings.TrimSuffix(name, "_dmspritedef")] = weights
+	}
+	return all, nil
+}
+
+// decodeWldMesh reads the name reference and vertex pieces of a mesh fragment
+func decodeWldMesh(data []byte, isOldWorld bool) (int32, Weights, error) {
+	r := &reader{r: bytes.NewReader(data)}
+	nameRef := r.int32()
+	// flags, material list, animation, two unknowns, center, three unknowns,
+	// max distance, min and max
+	r.skip(4 + 4 + 4 + 8 + 12 + 12 + 4 + 12 + 12)
+	vertexCount := r.uint16()
+	uvCount := r.uint16()
+	normalCount := r.uint16()
+	colorCount := r.uint16()
+	triangleCount := r.uint16()
+	pieceCount := r.uint16()
+	r.skip(8) // triangle and vertex material counts, animated vertex count, scale
+	uvSize := int64(8)
+	if isOldWorld {
+		uvSize = 4
+	}
+	r.skip(int64(vertexCount) * 6)
+	r.skip(int64(uvCount) * uvSize)
+	r.skip(int64(normalCount) * 3)
+	r.skip(int64(colorCount) * 4)
+	r.skip(int64(triangleCount) * 8)
+	w := make(Weights, 0, vertexCount)
+	for i := uint16(0); i < pieceCount; i++ {
+		count := r.uint16()
+		bone := r.uint16()
+		for j := uint16(0); j < count && len(w) < int(vertexCount); j++ {
+			w = append(w, []Influence{{Bone: int(bone), Weight: 1}})
+		}
+	}
+	if r.err != nil {
+		return 0, nil, fmt.Errorf("read vertex pieces: %w", r.err)
+	}
+	return nameRef, w, nil
+}
+
+// wldName returns the string at the negated nameRef offset of the decoded hash
+func wldName(hash []byte, nameRef int32) string {
+	offset := int(-nameRef)
+	if offset < 0 || offset >= len(hash) {
+		return ""
+	}
+	end := bytes.IndexByte(hash[offset:], 0)
+	if end < 0 {
+		return string(hash[offset:])
+	}
+	return string(hash[offset : offset+end])
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/xackery/engine/camera"
+	"github.com/xackery/engine/gui"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/engine/window"
+)
+
+type viewPreset struct {
+	name string
+	hint string // key hint shown in the menu
+	key  window.Key
+	mods window.ModifierKey
+	dir  math32.Vector3 // direction from target to camera
+	up   math32.Vector3
+}
+
+// viewPresets snap the camera along each axis, bound to numpad keys like most modelling tools
+var viewPresets = []*viewPreset{
+	{name: "Front", hint: "Num 1", key: window.KeyKP1, dir: math32.Vector3{X: 0, Y: 0, Z: 1}, up: math32.Vector3{X: 0, Y: 1, Z: 0}},
+	{name: "Back", hint: "Ctrl+Num 1", key: window.KeyKP1, mods: window.ModControl, dir: math32.Vector3{X: 0, Y: 0, Z: -1}, up: math32.Vector3{X: 0, Y: 1, Z: 0}},
+	{name: "Right", hint: "Num 3", key: window.KeyKP3, dir: math32.Vector3{X: 1, Y: 0, Z: 0}, up: math32.Vector3{X: 0, Y: 1, Z: 0}},
+	{name: "Left", hint: "Ctrl+Num 3", key: window.KeyKP3, mods: window.ModControl, dir: math32.Vector3{X: -1, Y: 0, Z: 0}, up: math32.Vector3{X: 0, Y: 1, Z: 0}},
+	{name: "Top", hint: "Num 7", key: window.KeyKP7, dir: math32.Vector3{X: 0, Y: 1, Z: 0}, up: math32.Vector3{X: 0, Y: 0, Z: -1}},
+	{name: "Bottom", hint: "Ctrl+Num 7", key: window.KeyKP7, mods: window.ModControl, dir: math32.Vector3{X: 0, Y: -1, Z: 0}, up: math32.Vector3{X: 0, Y: 0, Z: 1}},
+}
+
+// buildViewPresetMenu adds preset views and the projection toggle to the view menu
+func (gv *g3nView) buildViewPresetMenu(m *gui.Menu) {
+	presetMenu := gui.NewMenu()
+	for _, preset := range viewPresets {
+		preset := preset
+		// key handling is done below, menu shortcuts would fire a second time while the menu has focus
+		presetMenu.AddOption(fmt.Sprintf("%s (%s)", preset.name, preset.hint)).Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+			gv.snapView(preset)
+		})
+	}
+	m.AddMenu("Preset view", presetMenu)
+
+	gv.orthoItem = m.AddOption("Orthographic projection (Num 5)")
+	gv.orthoItem.SetIcon(getIcon(gv.cam.Projection() == camera.Orthographic))
+	gv.orthoItem.Subscribe(gui.OnClick, func(evname string, ev interface{}) {
+		gv.toggleProjection()
+	})
+	if gv.settings.Orthographic {
+		gv.setOrthographic(true)
+	}
+
+	// the gui manager only passes on keys while no edit or menu has key focus
+	gui.Manager().Subscribe(window.OnKeyDown, func(evname string, ev interface{}) {
+		kev := ev.(*window.KeyEvent)
+		if kev.Key == window.KeyKP5 && kev.Mods == 0 {
+			gv.toggleProjection()
+			return
+		}
+		for _, preset := range viewPresets {
+			if preset.key == kev.Key && preset.mods == kev.Mods {
+				gv.snapView(preset)
+				return
+			}
+		}
+	})
+}
+
+// snapView moves the orbit camera along a preset axis, keeping its distance to the target
+func (gv *g3nView) snapView(preset *viewPreset) {
+	gv.setFlyCamera(false)
+
+	target := gv.orbit.Target()
+	pos := gv.cam.Position()
+	dist := pos.DistanceTo(&target)
+	if dist < 1 {
+		dist = 1
+	}
+
+	newPos := preset.dir.Clone().MultiplyScalar(dist).Add(&target)
+	gv.cam.SetPositionVec(newPos)
+	gv.cam.LookAt(&target, &preset.up)
+	gv.cam.UpdateSize(dist)
+	fmt.Println("View", preset.name)
+}
+
+// toggleProjection switches the orbit camera between perspective and orthographic projection
+func (gv *g3nView) toggleProjection() {
+	gv.setOrthographic(gv.cam.Projection() != camera.Orthographic)
+}
+
+// setOrthographic sets the projection of the orbit camera, updating the View menu checkbox
+// and the saved setting
+func (gv *g3nView) setOrthographic(isOrtho bool) {
+	gv.orthoItem.SetIcon(getIcon(isOrtho))
+	if (gv.cam.Projection() == camera.Orthographic) == isOrtho {
+		return
+	}
+
+	target := gv.orbit.Target()
+	pos := gv.cam.Position()
+	dist := pos.DistanceTo(&target)
+	if dist < 1 {
+		dist = 1
+	}
+
+	defer gv.saveSettings()
+	if !isOrtho {
+		gv.cam.SetProjection(camera.Perspective)
+		gv.settings.Orthographic = false
+		fmt.Println("Perspective projection")
+		return
+	}
+	// match the orthographic size to the current perspective frustum at the target
+	gv.cam.UpdateSize(dist)
+	gv.cam.SetProjection(camera.Orthographic)
+	gv.settings.Orthographic = true
+	fmt.Println("Orthographic projection")
+}
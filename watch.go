@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	// watchInterval is how often watched files are checked
+	watchInterval = time.Second
+	// watchSettle is how long a changed file must stay unchanged before it is reloaded,
+	// so files still being written are not read
+	watchSettle = 500 * time.Millisecond
+)
+
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+	changed time.Time // when a change was first seen, zero if unchanged
+}
+
+// fileWatcher polls the modification time of loaded files
+type fileWatcher struct {
+	files     map[string]*watchedFile
+	lastCheck time.Time
+}
+
+func newFileWatcher() *fileWatcher {
+	return &fileWatcher{files: make(map[string]*watchedFile)}
+}
+
+// add starts watching path
+func (w *fileWatcher) add(path string) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		fmt.Println("Failed to watch", path, err)
+		return
+	}
+	w.files[path] = &watchedFile{modTime: fi.ModTime(), size: fi.Size()}
+}
+
+// remove stops watching path
+func (w *fileWatcher) remove(path string) {
+	delete(w.files, path)
+}
+
+// poll returns watched files that changed and have settled since the last poll
+func (w *fileWatcher) poll(now time.Time) []string {
+	if now.Sub(w.lastCheck) < watchInterval {
+		return nil
+	}
+	w.lastCheck = now
+
+	changed := []string{}
+	for path, wf := range w.files {
+		fi, err := os.Stat(path)
+		if err != nil {
+			// the file may be replaced by a rename, check again next poll
+			continue
+		}
+		if !fi.ModTime().Equal(wf.modTime) || fi.Size() != wf.size {
+			wf.modTime = fi.ModTime()
+			wf.size = fi.Size()
+			wf.changed = now
+			continue
+		}
+		if wf.changed.IsZero() || now.Sub(wf.changed) < watchSettle {
+			continue
+		}
+		wf.changed = time.Time{}
+		changed = append(changed, path)
+	}
+	return changed
+}
+
+// updateHotReload reloads loaded files changed on disk, called each frame
+func (gv *g3nView) updateHotReload() {
+	if !gv.settings.HotReload {
+		return
+	}
+	for _, path := range gv.watcher.poll(time.Now()) {
+		fmt.Println("Reloading", path)
+		err := gv.reload(path)
+		if err != nil {
+			gv.ed.Show(fmt.Sprintf("reload %s: %s", path, err))
+		}
+	}
+}
+
+// reload opens path again, keeping the camera pose, focused model and animation
+func (gv *g3nView) reload(path string) error {
+	if path == gv.archivePath {
+		pose := gv.cameraPose("reload")
+		focus := gv.focusName
+		animName := gv.animName
+		sources := gv.animSources
+		err := gv.openArchive(path)
+		if err != nil {
+			return err
+		}
+		// borrowed animations attach to the models as they're built
+		gv.animSources = sources
+		gv.restoreView(focus, pose, animName)
+		return nil
+	}
+
+	for i, modelPath := range gv.modelPaths {
+		if modelPath != path {
+			continue
+		}
+		model := gv.models[i]
+		gv.scene.Remove(model)
+		model.Dispose()
+		gv.models = append(gv.models[:i], gv.models[i+1:]...)
+		gv.modelPaths = append(gv.modelPaths[:i], gv.modelPaths[i+1:]...)
+		return gv.openModel(path)
+	}
+	return nil
+}
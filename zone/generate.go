@@ -0,0 +1,94 @@
+package zone
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/xackery/engine/core"
+	"github.com/xackery/engine/graphic"
+	"github.com/xackery/engine/light"
+	"github.com/xackery/engine/math32"
+	"github.com/xackery/quail/common"
+)
+
+// Generate instances the placeable objects of a zone at their recorded
+// positions, rotations and scales, and adds a point light per zone light.
+// Instances take their own references, meshes may be disposed afterwards
+func Generate(in *common.Zone, meshes map[string]*graphic.Mesh) (*core.Node, error) {
+	if in == nil {
+		return nil, fmt.Errorf("zone is nil")
+	}
+
+	root := core.NewNode()
+	root.SetName("zone")
+
+	templates := make(map[string]*graphic.Mesh)
+	names := make(map[string]string)
+	for name, mesh := range meshes {
+		templates[modelKey(name)] = mesh
+		names[modelKey(name)] = name
+	}
+
+	for _, obj := range in.Objects {
+		template, ok := templates[modelKey(obj.ModelName)]
+		if !ok {
+			fmt.Println("zone object", obj.Name, "references unknown model", obj.ModelName, "skipping")
+			continue
+		}
+
+		inst := template.Clone().(*graphic.Mesh)
+		// instances share the template geometry and materials, each holds a reference
+		inst.GetGeometry().Incref()
+		mats := inst.Materials()
+		for i := range mats {
+			mats[i].IMaterial().GetMaterial().Incref()
+		}
+		node := inst.GetNode()
+		node.SetName(obj.Name)
+		// the model name lets picking find the model data of an instance
+		node.SetUserData(names[modelKey(obj.ModelName)])
+		node.SetPosition(obj.Position.X, obj.Position.Y, obj.Position.Z)
+		// zon rotations are stored in degrees
+		node.SetRotation(math32.DegToRad(obj.Rotation.X), math32.DegToRad(obj.Rotation.Y), math32.DegToRad(obj.Rotation.Z))
+		scale := obj.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		node.SetScale(scale, scale, scale)
+		root.Add(inst)
+	}
+
+	for _, entry := range in.Lights {
+		pointLight := light.NewPoint(&math32.Color{R: entry.Color.X, G: entry.Color.Y, B: entry.Color.Z}, 1)
+		pointLight.SetName(entry.Name)
+		pointLight.SetPosition(entry.Position.X, entry.Position.Y, entry.Position.Z)
+		if entry.Radius > 0 {
+			pointLight.SetLinearDecay(1 / entry.Radius)
+			pointLight.SetQuadraticDecay(0)
+		}
+		root.Add(pointLight)
+	}
+
+	return root, nil
+}
+
+// IsPlaceable returns true if the model is instanced by a zone object
+func IsPlaceable(in *common.Zone, name string) bool {
+	if in == nil {
+		return false
+	}
+	key := modelKey(name)
+	for _, obj := range in.Objects {
+		if modelKey(obj.ModelName) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// modelKey normalizes a model name so object references match generated models
+func modelKey(name string) string {
+	name = strings.ToLower(name)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}